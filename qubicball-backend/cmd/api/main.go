@@ -9,6 +9,7 @@ import (
 	"qubicball-backend/internal/delivery/http"
 	"qubicball-backend/internal/delivery/http/handler"
 	"qubicball-backend/internal/infrastructure"
+	"qubicball-backend/internal/infrastructure/storage"
 	"qubicball-backend/internal/repository"
 	"qubicball-backend/internal/seeder"
 	"qubicball-backend/internal/usecase"
@@ -18,6 +19,9 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
+// deletedTaskRetention is how long soft-deleted tasks are kept before purging.
+const deletedTaskRetention = 30 * 24 * time.Hour
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -27,17 +31,48 @@ func main() {
 	// Infrastructure
 	db := infrastructure.NewDatabase()
 	redisClient := infrastructure.NewRedisClient()
+	blobStore := storage.NewBlobStore()
 
 	// Repository
 	userRepo := repository.NewUserRepository(db)
 	projectRepo := repository.NewProjectRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
+	sprintRepo := repository.NewSprintRepository(db)
+	customFieldRepo := repository.NewCustomFieldRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	taskDependencyRepo := repository.NewTaskDependencyRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	taskActivityRepo := repository.NewTaskActivityRepository(db)
+	taskRecurrenceRepo := repository.NewTaskRecurrenceRepository(db)
+	labelRepo := repository.NewLabelRepository(db)
+	timeEntryRepo := repository.NewTimeEntryRepository(db)
+	transactor := repository.NewTransactor(db)
 
 	// Usecase
 	timeoutContext := time.Duration(5) * time.Second
 	authUsecase := usecase.NewAuthUsecase(userRepo, timeoutContext)
-	projectUsecase := usecase.NewProjectUsecase(projectRepo, redisClient, timeoutContext)
-	taskUsecase := usecase.NewTaskUsecase(taskRepo, redisClient, timeoutContext)
+	projectUsecase := usecase.NewProjectUsecase(projectRepo, taskRepo, customFieldRepo, labelRepo, userRepo, auditLogRepo, transactor, redisClient, timeoutContext)
+	taskUsecase := usecase.NewTaskUsecase(taskRepo, projectRepo, userRepo, customFieldRepo, labelRepo, taskDependencyRepo, attachmentRepo, taskActivityRepo, taskRecurrenceRepo, blobStore, transactor, redisClient, timeoutContext)
+	sprintUsecase := usecase.NewSprintUsecase(sprintRepo, projectRepo, taskRepo, transactor, redisClient, timeoutContext)
+	customFieldUsecase := usecase.NewCustomFieldUsecase(customFieldRepo, projectRepo, taskRepo, userRepo, transactor, redisClient, timeoutContext)
+	projectBundleUsecase := usecase.NewProjectBundleUsecase(projectRepo, taskRepo, sprintRepo, customFieldRepo, labelRepo, userRepo, transactor, redisClient, timeoutContext)
+	timelineUsecase := usecase.NewTimelineUsecase(projectRepo, taskRepo, sprintRepo, taskDependencyRepo, timeoutContext)
+	reportUsecase := usecase.NewReportUsecase(projectRepo, snapshotRepo, timeoutContext)
+	searchUsecase := usecase.NewSearchUsecase(searchRepo, timeoutContext)
+	workflowUsecase := usecase.NewWorkflowUsecase(projectRepo, taskRepo, transactor, redisClient, timeoutContext)
+	taskDependencyUsecase := usecase.NewTaskDependencyUsecase(taskDependencyRepo, taskRepo, transactor, redisClient, timeoutContext)
+	commentUsecase := usecase.NewCommentUsecase(commentRepo, taskRepo, userRepo, notificationRepo, transactor, timeoutContext)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, timeoutContext)
+	taskActivityUsecase := usecase.NewTaskActivityUsecase(taskActivityRepo, taskRepo, projectRepo, timeoutContext)
+	taskRecurrenceUsecase := usecase.NewTaskRecurrenceUsecase(taskRecurrenceRepo, taskRepo, projectRepo, taskActivityRepo, transactor, redisClient, timeoutContext)
+	attachmentUsecase := usecase.NewAttachmentUsecase(attachmentRepo, taskRepo, blobStore, transactor, timeoutContext)
+	boardUsecase := usecase.NewBoardUsecase(projectRepo, taskRepo, redisClient, timeoutContext)
+	labelUsecase := usecase.NewLabelUsecase(labelRepo, projectRepo, transactor, redisClient, timeoutContext)
+	timeTrackingUsecase := usecase.NewTimeTrackingUsecase(timeEntryRepo, taskRepo, transactor, redisClient, timeoutContext)
 
 	// Seeding
 	log.Println("Seeding database...")
@@ -47,6 +82,22 @@ func main() {
 	authHandler := &handler.AuthHandler{UserUsecase: authUsecase}
 	projectHandler := &handler.ProjectHandler{ProjectUsecase: projectUsecase}
 	taskHandler := &handler.TaskHandler{TaskUsecase: taskUsecase}
+	sprintHandler := &handler.SprintHandler{SprintUsecase: sprintUsecase}
+	customFieldHandler := &handler.CustomFieldHandler{CustomFieldUsecase: customFieldUsecase}
+	projectBundleHandler := &handler.ProjectBundleHandler{ProjectBundleUsecase: projectBundleUsecase}
+	timelineHandler := &handler.TimelineHandler{TimelineUsecase: timelineUsecase}
+	reportHandler := &handler.ReportHandler{ReportUsecase: reportUsecase}
+	searchHandler := &handler.SearchHandler{SearchUsecase: searchUsecase}
+	taskDependencyHandler := &handler.TaskDependencyHandler{TaskDependencyUsecase: taskDependencyUsecase}
+	workflowHandler := &handler.WorkflowHandler{WorkflowUsecase: workflowUsecase}
+	commentHandler := &handler.CommentHandler{CommentUsecase: commentUsecase}
+	notificationHandler := &handler.NotificationHandler{NotificationUsecase: notificationUsecase}
+	attachmentHandler := &handler.AttachmentHandler{AttachmentUsecase: attachmentUsecase}
+	taskActivityHandler := &handler.TaskActivityHandler{TaskActivityUsecase: taskActivityUsecase}
+	taskRecurrenceHandler := &handler.TaskRecurrenceHandler{TaskRecurrenceUsecase: taskRecurrenceUsecase}
+	boardHandler := &handler.BoardHandler{BoardUsecase: boardUsecase}
+	timeTrackingHandler := &handler.TimeTrackingHandler{TimeTrackingUsecase: timeTrackingUsecase}
+	labelHandler := &handler.LabelHandler{LabelUsecase: labelUsecase}
 
 	// Router & Middleware
 	r := gin.Default()
@@ -54,7 +105,7 @@ func main() {
 
 	middleware := http.NewMiddleware(redisClient)
 
-	http.NewRouter(r, middleware, authHandler, projectHandler, taskHandler)
+	http.NewRouter(r, middleware, authHandler, projectHandler, taskHandler, sprintHandler, customFieldHandler, projectBundleHandler, timelineHandler, reportHandler, searchHandler, taskDependencyHandler, workflowHandler, commentHandler, notificationHandler, attachmentHandler, taskActivityHandler, taskRecurrenceHandler, boardHandler, timeTrackingHandler, labelHandler)
 
 	// Scheduler
 	c := cron.New()
@@ -68,7 +119,73 @@ func main() {
 	if err != nil {
 		log.Fatal("Error adding cron job:", err)
 	}
+	_, err = c.AddFunc("@every 10m", func() {
+		log.Println("Running Scheduler: Generating recurring tasks")
+		ctx := requestContext()
+		created, err := taskRecurrenceUsecase.GenerateDue(ctx)
+		if err != nil {
+			log.Printf("Error generating recurring tasks after %d created: %v", created, err)
+			return
+		}
+		log.Printf("Generated %d recurring tasks", created)
+	})
+	if err != nil {
+		log.Fatal("Error adding cron job:", err)
+	}
+	// Just before midnight so each snapshot reflects the end of its day
+	_, err = c.AddFunc("55 23 * * *", func() {
+		log.Println("Running Scheduler: Recording daily task snapshots")
+		ctx := requestContext()
+		if err := reportUsecase.RecordDailySnapshots(ctx); err != nil {
+			log.Printf("Error recording task snapshots: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatal("Error adding cron job:", err)
+	}
+	// Deleted tasks stay restorable for a while before they and their
+	// attachments are removed for good
+	_, err = c.AddFunc("30 3 * * *", func() {
+		log.Println("Running Scheduler: Purging deleted tasks")
+		ctx := requestContext()
+		purged, err := taskUsecase.PurgeDeletedTasks(ctx, time.Now().Add(-deletedTaskRetention))
+		if err != nil {
+			log.Printf("Error purging deleted tasks after %d purged: %v", purged, err)
+			return
+		}
+		log.Printf("Purged %d deleted tasks", purged)
+	})
+	if err != nil {
+		log.Fatal("Error adding cron job:", err)
+	}
+	// Ranks lengthen as tasks are dragged into the same spot; existing tasks
+	// start out unranked, so this also runs once at startup
+	rebalanceRanks := func() {
+		log.Println("Running Scheduler: Rebalancing task ranks")
+		ctx := requestContext()
+		rebalanced, err := taskUsecase.RebalanceRanks(ctx)
+		if err != nil {
+			log.Printf("Error rebalancing task ranks after %d projects: %v", rebalanced, err)
+			return
+		}
+		log.Printf("Rebalanced task ranks in %d projects", rebalanced)
+	}
+	_, err = c.AddFunc("@hourly", rebalanceRanks)
+	if err != nil {
+		log.Fatal("Error adding cron job:", err)
+	}
+	// Running timers live in Redis; the snapshot lets them survive losing it
+	_, err = c.AddFunc("@every 1m", func() {
+		ctx := requestContext()
+		if _, err := timeTrackingUsecase.PersistTimers(ctx); err != nil {
+			log.Printf("Error persisting running timers: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatal("Error adding cron job:", err)
+	}
 	c.Start()
+	go rebalanceRanks()
 
 	// Start Server
 	port := os.Getenv("PORT")
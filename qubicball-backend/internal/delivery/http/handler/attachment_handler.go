@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the form encoding around an upload at the
+// size limit.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	AttachmentUsecase domain.AttachmentUsecase
+}
+
+func (h *AttachmentHandler) GetByTaskID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	attachments, err := h.AttachmentUsecase.GetByTaskID(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxAttachmentSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the \"file\" field"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, created, err := h.AttachmentUsecase.Upload(c.Request.Context(), uint(id), domain.AttachmentUpload{
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Content:     file,
+	})
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, attachment)
+}
+
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	attachmentID, _ := strconv.Atoi(c.Param("attachment_id"))
+	attachment, content, err := h.AttachmentUsecase.Open(c.Request.Context(), uint(id), uint(attachmentID))
+	if err != nil {
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment content is missing"})
+			return
+		}
+		writeError(c, err, "Attachment not found")
+		return
+	}
+	defer content.Close()
+
+	// Always served as a download so uploaded content can't run in the app's
+	// origin
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	attachmentID, _ := strconv.Atoi(c.Param("attachment_id"))
+	if err := h.AttachmentUsecase.Delete(c.Request.Context(), uint(id), uint(attachmentID)); err != nil {
+		writeError(c, err, "Attachment not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
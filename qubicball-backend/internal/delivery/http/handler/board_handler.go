@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BoardHandler struct {
+	BoardUsecase domain.BoardUsecase
+}
+
+// GetBoard returns the project's kanban board. Members only see their own
+// tasks as cards, as in task listings.
+func (h *BoardHandler) GetBoard(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+
+	var assigneeID *uint
+	if roleVal, exists := c.Get("role"); exists && roleVal.(domain.Role) == domain.RoleMember {
+		userIDVal, _ := c.Get("user_id")
+		userID := userIDVal.(uint)
+		assigneeID = &userID
+	}
+
+	board, err := h.BoardUsecase.GetBoard(c.Request.Context(), uint(projectID), assigneeID)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// SetWIPLimits replaces the project's column limits with a status to limit
+// map; an empty map removes them all.
+func (h *BoardHandler) SetWIPLimits(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	var limits domain.WIPLimits
+	if err := c.ShouldBindJSON(&limits); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.BoardUsecase.SetWIPLimits(c.Request.Context(), uint(projectID), limits)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	CommentUsecase domain.CommentUsecase
+}
+
+type commentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (h *CommentHandler) GetByTaskID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	comments, err := h.CommentUsecase.GetByTaskID(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func (h *CommentHandler) Create(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req commentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.CommentUsecase.Create(c.Request.Context(), uint(id), req.Body)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *CommentHandler) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	commentID, _ := strconv.Atoi(c.Param("comment_id"))
+	var req commentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.CommentUsecase.Update(c.Request.Context(), uint(id), uint(commentID), req.Body)
+	if err != nil {
+		writeError(c, err, "Comment not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	commentID, _ := strconv.Atoi(c.Param("comment_id"))
+	if err := h.CommentUsecase.Delete(c.Request.Context(), uint(id), uint(commentID)); err != nil {
+		writeError(c, err, "Comment not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+func (h *CommentHandler) GetHistory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	commentID, _ := strconv.Atoi(c.Param("comment_id"))
+	revisions, err := h.CommentUsecase.GetHistory(c.Request.Context(), uint(id), uint(commentID))
+	if err != nil {
+		writeError(c, err, "Comment not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// customFieldUpdateRequest edits a field; omitted properties are kept.
+type customFieldUpdateRequest struct {
+	Name     string                 `json:"name"`
+	Type     domain.CustomFieldType `json:"type"`
+	Options  domain.StringList      `json:"options"`
+	Required *bool                  `json:"required"`
+	Position *int                   `json:"position"`
+}
+
+type CustomFieldHandler struct {
+	CustomFieldUsecase domain.CustomFieldUsecase
+}
+
+func (h *CustomFieldHandler) Create(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	var field domain.CustomField
+	if err := c.ShouldBindJSON(&field); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	field.ID = 0
+	field.ProjectID = uint(projectID)
+
+	if err := h.CustomFieldUsecase.Create(c.Request.Context(), &field); err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, field)
+}
+
+func (h *CustomFieldHandler) GetByProjectID(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	fields, err := h.CustomFieldUsecase.GetByProjectID(c.Request.Context(), uint(projectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fields)
+}
+
+func (h *CustomFieldHandler) Update(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("field_id"))
+	var req customFieldUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes := domain.CustomFieldChanges{
+		Name:     req.Name,
+		Type:     req.Type,
+		Options:  req.Options,
+		Required: req.Required,
+		Position: req.Position,
+	}
+	field, err := h.CustomFieldUsecase.Update(c.Request.Context(), uint(projectID), uint(id), changes)
+	if err != nil {
+		writeError(c, err, "Custom field not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, field)
+}
+
+func (h *CustomFieldHandler) Delete(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("field_id"))
+	if err := h.CustomFieldUsecase.Delete(c.Request.Context(), uint(projectID), uint(id)); err != nil {
+		writeError(c, err, "Custom field not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom field deleted"})
+}
+
+// SetTaskValues accepts an object of field ID to value, e.g. {"3": "ACME", "4": null}.
+func (h *CustomFieldHandler) SetTaskValues(c *gin.Context) {
+	taskID, _ := strconv.Atoi(c.Param("id"))
+	var values map[uint]json.RawMessage
+	if err := c.ShouldBindJSON(&values); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stored, err := h.CustomFieldUsecase.SetTaskValues(c.Request.Context(), uint(taskID), values)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, stored)
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// writeError maps usecase errors onto HTTP responses. notFound is the message
+// used when the requested record does not exist.
+func writeError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
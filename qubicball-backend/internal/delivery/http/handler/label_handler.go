@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	LabelUsecase domain.LabelUsecase
+}
+
+type mergeLabelRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+func (h *LabelHandler) Create(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	var label domain.Label
+	if err := c.ShouldBindJSON(&label); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	label.ProjectID = uint(projectID)
+
+	if err := h.LabelUsecase.Create(c.Request.Context(), &label); err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+func (h *LabelHandler) GetByProjectID(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	labels, err := h.LabelUsecase.GetByProjectID(c.Request.Context(), uint(projectID))
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+// Update renames or recolors a label; the change shows on every task
+// carrying it.
+func (h *LabelHandler) Update(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("label_id"))
+	var label domain.Label
+	if err := c.ShouldBindJSON(&label); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	label.ID = uint(id)
+	label.ProjectID = uint(projectID)
+
+	if err := h.LabelUsecase.Update(c.Request.Context(), &label); err != nil {
+		writeError(c, err, "Label not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+func (h *LabelHandler) Delete(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("label_id"))
+	if err := h.LabelUsecase.Delete(c.Request.Context(), uint(projectID), uint(id)); err != nil {
+		writeError(c, err, "Label not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted"})
+}
+
+// Merge relabels the label's tasks with target_id and deletes the label,
+// returning the target.
+func (h *LabelHandler) Merge(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("label_id"))
+	var req mergeLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.LabelUsecase.Merge(c.Request.Context(), uint(projectID), uint(id), req.TargetID)
+	if err != nil {
+		writeError(c, err, "Label not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
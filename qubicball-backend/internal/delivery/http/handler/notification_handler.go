@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	NotificationUsecase domain.NotificationUsecase
+}
+
+func (h *NotificationHandler) List(c *gin.Context) {
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+	notifications, err := h.NotificationUsecase.List(c.Request.Context(), unreadOnly)
+	if err != nil {
+		writeError(c, err, "Notifications not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.NotificationUsecase.MarkRead(c.Request.Context(), uint(id)); err != nil {
+		writeError(c, err, "Notification not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	count, err := h.NotificationUsecase.MarkAllRead(c.Request.Context())
+	if err != nil {
+		writeError(c, err, "Notifications not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": count})
+}
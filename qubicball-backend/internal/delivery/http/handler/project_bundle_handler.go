@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectBundleHandler struct {
+	ProjectBundleUsecase domain.ProjectBundleUsecase
+}
+
+func (h *ProjectBundleHandler) Export(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	bundle, err := h.ProjectBundleUsecase.Export(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	filename := fmt.Sprintf("project-%d-%s.json", id, bundle.ExportedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, bundle)
+}
+
+func (h *ProjectBundleHandler) Import(c *gin.Context) {
+	var bundle domain.ProjectBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	report, err := h.ProjectBundleUsecase.Import(c.Request.Context(), &bundle, dryRun)
+	if err != nil {
+		writeError(c, err, "Not found")
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"qubicball-backend/internal/domain"
 
@@ -36,7 +37,13 @@ func (h *ProjectHandler) GetAll(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	projects, err := h.ProjectUsecase.GetAll(c.Request.Context(), page, pageSize)
+	filter := domain.ProjectFilter{
+		Templates:     c.Query("templates") == "true",
+		UserID:        c.GetUint("user_id"),
+		FavoritesOnly: c.Query("favorites") == "true",
+	}
+
+	projects, err := h.ProjectUsecase.GetAll(c.Request.Context(), filter, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -86,3 +93,179 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Project deleted"})
 }
+
+func (h *ProjectHandler) Clone(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		Name        string        `json:"name"`
+		Description *string       `json:"description"`
+		StartDate   *time.Time    `json:"start_date"`
+		AssigneeMap map[uint]uint `json:"assignee_map"`
+		AsTemplate  bool          `json:"as_template"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.clone(c, uint(id), domain.ProjectCloneOptions{
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     c.GetUint("user_id"),
+		AsTemplate:  req.AsTemplate,
+		StartDate:   req.StartDate,
+		AssigneeMap: req.AssigneeMap,
+	})
+}
+
+func (h *ProjectHandler) SaveAsTemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		Name string `json:"name"`
+	}
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	h.clone(c, uint(id), domain.ProjectCloneOptions{
+		Name:       req.Name,
+		OwnerID:    c.GetUint("user_id"),
+		AsTemplate: true,
+	})
+}
+
+func (h *ProjectHandler) clone(c *gin.Context, sourceID uint, opts domain.ProjectCloneOptions) {
+	project, err := h.ProjectUsecase.Clone(c.Request.Context(), sourceID, opts)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+func (h *ProjectHandler) GetStats(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+
+	stats, err := h.ProjectUsecase.GetStats(c.Request.Context(), uint(id), days)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *ProjectHandler) TransferOwnership(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		NewOwnerID uint `json:"new_owner_id" binding:"required"`
+		Version    int  `json:"version" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.ProjectUsecase.TransferOwnership(c.Request.Context(), uint(id), req.NewOwnerID, req.Version)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateSettings applies the given settings over the project's current ones,
+// so fields left out keep their values.
+func (h *ProjectHandler) UpdateSettings(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	project, err := h.ProjectUsecase.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	settings := project.Settings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err = h.ProjectUsecase.UpdateSettings(c.Request.Context(), uint(id), settings)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) GetCacheMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"project_list": h.ProjectUsecase.ListCacheMetrics()})
+}
+
+func (h *ProjectHandler) SetFavorite(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		Pinned bool `json:"pinned"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	favorite, err := h.ProjectUsecase.SetFavorite(c.Request.Context(), uint(id), req.Pinned)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, favorite)
+}
+
+func (h *ProjectHandler) RemoveFavorite(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.ProjectUsecase.RemoveFavorite(c.Request.Context(), uint(id)); err != nil {
+		writeError(c, err, "Project is not in your favorites")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project removed from favorites"})
+}
+
+func (h *ProjectHandler) ReorderFavorites(c *gin.Context) {
+	var req struct {
+		ProjectIDs []uint `json:"project_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.ProjectUsecase.ReorderFavorites(c.Request.Context(), req.ProjectIDs); err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Favorites reordered"})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	ReportUsecase domain.ReportUsecase
+}
+
+// parseDateRange reads from/to (YYYY-MM-DD), defaulting to the last 30 days.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := to.AddDate(0, 0, -29)
+
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", v)
+		}
+		to = t
+		from = to.AddDate(0, 0, -29)
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", v)
+		}
+		from = t
+	}
+	return from, to, nil
+}
+
+func (h *ReportHandler) GetBurndown(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	burndown, err := h.ReportUsecase.GetBurndown(c.Request.Context(), uint(projectID), from, to)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, burndown)
+}
+
+func (h *ReportHandler) GetCumulativeFlow(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flow, err := h.ReportUsecase.GetCumulativeFlow(c.Request.Context(), uint(projectID), from, to)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	SearchUsecase domain.SearchUsecase
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	var opts domain.SearchOptions
+	for _, t := range splitQueryList(c.QueryArray("type")) {
+		opts.Types = append(opts.Types, domain.SearchResultType(t))
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		opts.Limit = limit
+	}
+
+	results, err := h.SearchUsecase.Search(c.Request.Context(), c.Query("q"), opts)
+	if err != nil {
+		writeError(c, err, "Not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
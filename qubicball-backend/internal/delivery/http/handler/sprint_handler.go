@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SprintHandler struct {
+	SprintUsecase domain.SprintUsecase
+}
+
+func (h *SprintHandler) Create(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	var sprint domain.Sprint
+	if err := c.ShouldBindJSON(&sprint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sprint.ID = 0
+	sprint.ProjectID = uint(projectID)
+
+	if err := h.SprintUsecase.Create(c.Request.Context(), &sprint); err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, sprint)
+}
+
+func (h *SprintHandler) GetByProjectID(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	sprints, err := h.SprintUsecase.GetByProjectID(c.Request.Context(), uint(projectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sprints)
+}
+
+func (h *SprintHandler) GetByID(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	sprint, err := h.SprintUsecase.GetByID(c.Request.Context(), uint(projectID), uint(id))
+	if err != nil {
+		writeError(c, err, "Sprint not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (h *SprintHandler) Update(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	var sprint domain.Sprint
+	if err := c.ShouldBindJSON(&sprint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sprint.ID = uint(id)
+	sprint.ProjectID = uint(projectID)
+
+	if err := h.SprintUsecase.Update(c.Request.Context(), &sprint); err != nil {
+		writeError(c, err, "Sprint not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (h *SprintHandler) Delete(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	if err := h.SprintUsecase.Delete(c.Request.Context(), uint(projectID), uint(id)); err != nil {
+		writeError(c, err, "Sprint not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint deleted"})
+}
+
+func (h *SprintHandler) Start(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	sprint, err := h.SprintUsecase.Start(c.Request.Context(), uint(projectID), uint(id))
+	if err != nil {
+		writeError(c, err, "Sprint not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (h *SprintHandler) Complete(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	var req struct {
+		CarryOverTo *uint `json:"carry_over_to"` // omit to move unfinished tasks to the backlog
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.SprintUsecase.Complete(c.Request.Context(), uint(projectID), uint(id), req.CarryOverTo)
+	if err != nil {
+		writeError(c, err, "Sprint not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *SprintHandler) AssignTasks(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	var req struct {
+		TaskIDs []uint `json:"task_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assigned, err := h.SprintUsecase.AssignTasks(c.Request.Context(), uint(projectID), uint(id), req.TaskIDs)
+	if err != nil {
+		writeError(c, err, "Sprint not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assigned": assigned})
+}
+
+func (h *SprintHandler) RemoveTask(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	id, _ := strconv.Atoi(c.Param("sprint_id"))
+	taskID, _ := strconv.Atoi(c.Param("task_id"))
+	if err := h.SprintUsecase.RemoveTask(c.Request.Context(), uint(projectID), uint(id), uint(taskID)); err != nil {
+		writeError(c, err, "Task not found in sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task removed from sprint"})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaskActivityHandler struct {
+	TaskActivityUsecase domain.TaskActivityUsecase
+}
+
+// parseActivityQuery reads limit and before (an activity ID, as returned in
+// X-Next-Cursor).
+func parseActivityQuery(c *gin.Context) (domain.TaskActivityQuery, error) {
+	var query domain.TaskActivityQuery
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, err
+		}
+		query.Limit = limit
+	}
+	if raw := c.Query("before"); raw != "" {
+		before, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return query, err
+		}
+		query.BeforeID = uint(before)
+	}
+	return query, nil
+}
+
+func writeActivityPage(c *gin.Context, page *domain.TaskActivityPage) {
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	activities := page.Activities
+	if activities == nil {
+		activities = []domain.TaskActivity{}
+	}
+	c.JSON(http.StatusOK, activities)
+}
+
+func (h *TaskActivityHandler) GetByTaskID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	query, err := parseActivityQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit and before must be numbers"})
+		return
+	}
+	page, err := h.TaskActivityUsecase.GetByTaskID(c.Request.Context(), uint(id), query)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	writeActivityPage(c, page)
+}
+
+func (h *TaskActivityHandler) GetByProjectID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	query, err := parseActivityQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit and before must be numbers"})
+		return
+	}
+	page, err := h.TaskActivityUsecase.GetByProjectID(c.Request.Context(), uint(id), query)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	writeActivityPage(c, page)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaskDependencyHandler struct {
+	TaskDependencyUsecase domain.TaskDependencyUsecase
+}
+
+func (h *TaskDependencyHandler) GetDependencies(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	dependencies, err := h.TaskDependencyUsecase.GetDependencies(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, dependencies)
+}
+
+func (h *TaskDependencyHandler) AddBlocker(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		BlockerID uint `json:"blocker_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dependencies, err := h.TaskDependencyUsecase.AddBlocker(c.Request.Context(), uint(id), req.BlockerID)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dependencies)
+}
+
+func (h *TaskDependencyHandler) RemoveBlocker(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	blockerID, _ := strconv.Atoi(c.Param("blocker_id"))
+	if err := h.TaskDependencyUsecase.RemoveBlocker(c.Request.Context(), uint(id), uint(blockerID)); err != nil {
+		writeError(c, err, "Dependency not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency removed"})
+}
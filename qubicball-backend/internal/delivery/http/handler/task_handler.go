@@ -2,8 +2,11 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"qubicball-backend/internal/domain"
 
@@ -23,7 +26,7 @@ func (h *TaskHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.TaskUsecase.Create(c.Request.Context(), &task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err, "Project not found")
 		return
 	}
 
@@ -33,25 +36,46 @@ func (h *TaskHandler) Create(c *gin.Context) {
 func (h *TaskHandler) GetByProjectID(c *gin.Context) {
 	projectID, _ := strconv.Atoi(c.Param("project_id"))
 
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	customFields, err := parseCustomFieldQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	roleVal, exists := c.Get("role")
 	// Safe type assertion
-	var tasks []domain.Task
-	var err error
+	var page *domain.TaskPage
 
 	if exists && roleVal.(domain.Role) == domain.RoleMember {
 		userIDVal, _ := c.Get("user_id")
 		userID := userIDVal.(uint)
-		tasks, err = h.TaskUsecase.GetByProjectIDAndAssigneeID(c.Request.Context(), uint(projectID), userID)
+		page, err = h.TaskUsecase.GetByProjectIDAndAssigneeID(c.Request.Context(), uint(projectID), userID, query, customFields)
 	} else {
-		tasks, err = h.TaskUsecase.GetByProjectID(c.Request.Context(), uint(projectID))
+		page, err = h.TaskUsecase.GetByProjectID(c.Request.Context(), uint(projectID), query, customFields)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err, "Project not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	writeTaskPage(c, page)
+}
+
+func (h *TaskHandler) GetSubtree(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	tree, err := h.TaskUsecase.GetSubtree(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
 }
 
 func (h *TaskHandler) Update(c *gin.Context) {
@@ -67,7 +91,7 @@ func (h *TaskHandler) Update(c *gin.Context) {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Task modified by another user or not found. Please refresh and try again."})
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeError(c, err, "Task not found")
 		}
 		return
 	}
@@ -75,6 +99,85 @@ func (h *TaskHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, task)
 }
 
+// Move reorders a task within its project, placing it directly after
+// after_id and/or before before_id. Board moves also pass the target column
+// as status, along with the task's version.
+func (h *TaskHandler) Move(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var move domain.TaskMove
+	if err := c.ShouldBindJSON(&move); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := h.TaskUsecase.Move(c.Request.Context(), uint(id), move)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// Bulk applies one action to up to 100 tasks, each named with the version
+// the client last saw. It is all or nothing: when any task fails nothing is
+// changed and the report comes back with 409, giving every task's outcome.
+func (h *TaskHandler) Bulk(c *gin.Context) {
+	var op domain.TaskBulkOperation
+	if err := c.ShouldBindJSON(&op); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.TaskUsecase.Bulk(c.Request.Context(), op)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Applied {
+		status = http.StatusConflict
+	}
+	c.JSON(status, report)
+}
+
+// Watch adds a watcher to the task, the caller unless user_id is given.
+func (h *TaskHandler) Watch(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var input struct {
+		UserID uint `json:"user_id"`
+	}
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	watchers, err := h.TaskUsecase.Watch(c.Request.Context(), uint(id), input.UserID)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, watchers)
+}
+
+func (h *TaskHandler) Unwatch(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := strconv.Atoi(c.Param("user_id"))
+
+	watchers, err := h.TaskUsecase.Unwatch(c.Request.Context(), uint(id), uint(userID))
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, watchers)
+}
+
 func (h *TaskHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	if err := h.TaskUsecase.Delete(c.Request.Context(), uint(id)); err != nil {
@@ -89,11 +192,156 @@ func (h *TaskHandler) GetByAssigneeID(c *gin.Context) {
 	assigneeID, _ := strconv.Atoi(c.Param("assignee_id"))
 	// If assignee_id is not passed in route, maybe from query or context (me)?
 	// Route will be /tasks/assignee/:assignee_id
-	tasks, err := h.TaskUsecase.GetByAssigneeID(c.Request.Context(), uint(assigneeID))
+	query, err := parseTaskQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	page, err := h.TaskUsecase.GetByAssigneeID(c.Request.Context(), uint(assigneeID), query)
+	if err != nil {
+		writeError(c, err, "Tasks not found")
+		return
+	}
+
+	writeTaskPage(c, page)
+}
+
+// writeTaskPage responds with the page's tasks as a plain array, as before
+// pagination existed, and passes the next cursor in a header.
+func writeTaskPage(c *gin.Context, page *domain.TaskPage) {
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	tasks := page.Tasks
+	if tasks == nil {
+		tasks = []domain.Task{}
+	}
 	c.JSON(http.StatusOK, tasks)
 }
+
+// parseTaskQuery reads the listing filters, sort and paging parameters:
+//
+//	status=Completed,In Progress    priority=high,urgent    assignee=3,none    label=4,7    q=text
+//	due_from, due_to, created_from, created_to, updated_from, updated_to
+//	sort=rank|priority|due_date|status|updated_at|created_at (prefix "-" for descending)
+//	limit, cursor
+//
+// Multi-valued parameters accept commas or repetition. A date-only *_to
+// bound includes that whole day.
+func parseTaskQuery(c *gin.Context) (domain.TaskQuery, error) {
+	var query domain.TaskQuery
+
+	for _, status := range splitQueryList(c.QueryArray("status")) {
+		query.Filter.Statuses = append(query.Filter.Statuses, domain.TaskStatus(status))
+	}
+	for _, priority := range splitQueryList(c.QueryArray("priority")) {
+		if !domain.TaskPriority(priority).Valid() {
+			return query, fmt.Errorf("invalid priority %q", priority)
+		}
+		query.Filter.Priorities = append(query.Filter.Priorities, domain.TaskPriority(priority))
+	}
+	for _, assignee := range splitQueryList(c.QueryArray("assignee")) {
+		if assignee == "none" {
+			query.Filter.Unassigned = true
+			continue
+		}
+		id, err := strconv.ParseUint(assignee, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid assignee %q, expected a user ID or \"none\"", assignee)
+		}
+		query.Filter.AssigneeIDs = append(query.Filter.AssigneeIDs, uint(id))
+	}
+	for _, label := range splitQueryList(c.QueryArray("label")) {
+		id, err := strconv.ParseUint(label, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid label %q, expected a label ID", label)
+		}
+		query.Filter.LabelIDs = append(query.Filter.LabelIDs, uint(id))
+	}
+	query.Filter.Text = strings.TrimSpace(c.Query("q"))
+
+	ranges := []struct {
+		name     string
+		from, to **time.Time
+	}{
+		{"due", &query.Filter.DueFrom, &query.Filter.DueTo},
+		{"created", &query.Filter.CreatedFrom, &query.Filter.CreatedTo},
+		{"updated", &query.Filter.UpdatedFrom, &query.Filter.UpdatedTo},
+	}
+	for _, rg := range ranges {
+		from, _, err := parseQueryTime(c, rg.name+"_from")
+		if err != nil {
+			return query, err
+		}
+		to, dateOnly, err := parseQueryTime(c, rg.name+"_to")
+		if err != nil {
+			return query, err
+		}
+		if to != nil && dateOnly {
+			next := to.AddDate(0, 0, 1)
+			to = &next
+		}
+		*rg.from, *rg.to = from, to
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		query.Sort.Desc = strings.HasPrefix(sort, "-")
+		query.Sort.Field = domain.TaskSortField(strings.TrimPrefix(sort, "-"))
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return query, fmt.Errorf("invalid limit %q", v)
+		}
+		query.Limit = limit
+	}
+	query.Cursor = c.Query("cursor")
+
+	return query, nil
+}
+
+func splitQueryList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// parseQueryTime reads a YYYY-MM-DD or RFC 3339 query parameter and reports
+// whether it was a plain date.
+func parseQueryTime(c *gin.Context, name string) (*time.Time, bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, false, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return &t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD or RFC 3339", name, v)
+	}
+	return &t, false, nil
+}
+
+// parseCustomFieldQuery collects cf.<field_id>=<value> query parameters.
+func parseCustomFieldQuery(c *gin.Context) (map[uint]string, error) {
+	filters := make(map[uint]string)
+	for key, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(key, "cf.") || len(values) == 0 {
+			continue
+		}
+		fieldID, err := strconv.ParseUint(strings.TrimPrefix(key, "cf."), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom field filter %q", key)
+		}
+		filters[uint(fieldID)] = values[0]
+	}
+	return filters, nil
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaskRecurrenceHandler struct {
+	TaskRecurrenceUsecase domain.TaskRecurrenceUsecase
+}
+
+func (h *TaskRecurrenceHandler) GetRecurrence(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	recurrence, err := h.TaskRecurrenceUsecase.GetRecurrence(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Recurrence not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+func (h *TaskRecurrenceHandler) SetRecurrence(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		Rule     string `json:"rule" binding:"required"` // RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=MO
+		EndAfter int    `json:"end_after"`               // Occurrences, counting this task
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurrence, err := h.TaskRecurrenceUsecase.SetRecurrence(c.Request.Context(), uint(id), req.Rule, req.EndAfter)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+func (h *TaskRecurrenceHandler) Skip(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	recurrence, err := h.TaskRecurrenceUsecase.Skip(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Recurrence not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+func (h *TaskRecurrenceHandler) Stop(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	recurrence, err := h.TaskRecurrenceUsecase.Stop(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Recurrence not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimeTrackingHandler struct {
+	TimeTrackingUsecase domain.TimeTrackingUsecase
+}
+
+// timeEntryRequest logs or edits time. started_at defaults to the minutes
+// ending now when logging; on edit, omitted fields are kept and an empty
+// note clears it.
+type timeEntryRequest struct {
+	Minutes   int       `json:"minutes"`
+	StartedAt time.Time `json:"started_at"`
+	Note      *string   `json:"note"`
+}
+
+type startTimerRequest struct {
+	TaskID uint   `json:"task_id" binding:"required"`
+	Note   string `json:"note"`
+}
+
+func (h *TimeTrackingHandler) GetEntries(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	entries, err := h.TimeTrackingUsecase.GetEntries(c.Request.Context(), uint(id))
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *TimeTrackingHandler) LogTime(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req timeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry := &domain.TimeEntry{Minutes: req.Minutes, StartedAt: req.StartedAt}
+	if req.Note != nil {
+		entry.Note = *req.Note
+	}
+	if err := h.TimeTrackingUsecase.LogTime(c.Request.Context(), uint(id), entry); err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *TimeTrackingHandler) UpdateEntry(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	entryID, _ := strconv.Atoi(c.Param("entry_id"))
+	var req timeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes := domain.TimeEntryChanges{Minutes: req.Minutes, StartedAt: req.StartedAt, Note: req.Note}
+	entry, err := h.TimeTrackingUsecase.UpdateEntry(c.Request.Context(), uint(id), uint(entryID), changes)
+	if err != nil {
+		writeError(c, err, "Time entry not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *TimeTrackingHandler) DeleteEntry(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	entryID, _ := strconv.Atoi(c.Param("entry_id"))
+	if err := h.TimeTrackingUsecase.DeleteEntry(c.Request.Context(), uint(id), uint(entryID)); err != nil {
+		writeError(c, err, "Time entry not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time entry deleted"})
+}
+
+func (h *TimeTrackingHandler) GetTimer(c *gin.Context) {
+	timer, err := h.TimeTrackingUsecase.GetTimer(c.Request.Context())
+	if err != nil {
+		writeError(c, err, "No timer is running")
+		return
+	}
+
+	c.JSON(http.StatusOK, timer)
+}
+
+func (h *TimeTrackingHandler) StartTimer(c *gin.Context) {
+	var req startTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timer, err := h.TimeTrackingUsecase.StartTimer(c.Request.Context(), req.TaskID, req.Note)
+	if err != nil {
+		writeError(c, err, "Task not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, timer)
+}
+
+// StopTimer stops the running timer and returns the time entry it logged.
+func (h *TimeTrackingHandler) StopTimer(c *gin.Context) {
+	entry, err := h.TimeTrackingUsecase.StopTimer(c.Request.Context())
+	if err != nil {
+		writeError(c, err, "No timer is running")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetReport totals logged time over from/to (YYYY-MM-DD, both inclusive),
+// grouped by group_by: user (default), project, task or day. project_id and
+// user_id narrow it; members only ever see their own time.
+func (h *TimeTrackingHandler) GetReport(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := domain.TimeReportQuery{From: from, To: to, GroupBy: domain.TimeReportGroup(c.Query("group_by"))}
+	for _, param := range []struct {
+		name   string
+		target **uint
+	}{
+		{"project_id", &query.ProjectID},
+		{"user_id", &query.UserID},
+	} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s %q", param.name, raw)})
+			return
+		}
+		value := uint(id)
+		*param.target = &value
+	}
+
+	report, err := h.TimeTrackingUsecase.GetReport(c.Request.Context(), query)
+	if err != nil {
+		writeError(c, err, "Report not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimelineHandler struct {
+	TimelineUsecase domain.TimelineUsecase
+}
+
+func (h *TimelineHandler) GetProjectTimeline(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	groupBy := domain.TimelineGrouping(c.DefaultQuery("group_by", string(domain.TimelineByAssignee)))
+
+	// Members only see their own tasks, as in TaskHandler.GetByProjectID
+	var assigneeID *uint
+	if role, _ := c.Get("role"); role == domain.RoleMember {
+		userID := c.GetUint("user_id")
+		assigneeID = &userID
+	}
+
+	timeline, err := h.TimelineUsecase.GetProjectTimeline(c.Request.Context(), uint(projectID), groupBy, assigneeID)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkflowHandler struct {
+	WorkflowUsecase domain.WorkflowUsecase
+}
+
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	workflow, err := h.WorkflowUsecase.GetWorkflow(c.Request.Context(), uint(projectID))
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	var workflow domain.Workflow
+	if err := c.ShouldBindJSON(&workflow); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.WorkflowUsecase.UpdateWorkflow(c.Request.Context(), uint(projectID), &workflow)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ResetWorkflow puts the project back on the default workflow.
+func (h *WorkflowHandler) ResetWorkflow(c *gin.Context) {
+	projectID, _ := strconv.Atoi(c.Param("id"))
+	workflow, err := h.WorkflowUsecase.UpdateWorkflow(c.Request.Context(), uint(projectID), nil)
+	if err != nil {
+		writeError(c, err, "Project not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
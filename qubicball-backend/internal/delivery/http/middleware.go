@@ -72,6 +72,7 @@ func (m *Middleware) AuthMiddleware(roles ...domain.Role) gin.HandlerFunc {
 
 		c.Set("user_id", userID)
 		c.Set("role", userRole)
+		c.Request = c.Request.WithContext(domain.ContextWithActor(c.Request.Context(), domain.Actor{UserID: userID, Role: userRole}))
 
 		if len(roles) > 0 {
 			authorized := false
@@ -121,6 +122,7 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -13,6 +13,22 @@ func NewRouter(
 	authHandler *handler.AuthHandler,
 	projectHandler *handler.ProjectHandler,
 	taskHandler *handler.TaskHandler,
+	sprintHandler *handler.SprintHandler,
+	customFieldHandler *handler.CustomFieldHandler,
+	projectBundleHandler *handler.ProjectBundleHandler,
+	timelineHandler *handler.TimelineHandler,
+	reportHandler *handler.ReportHandler,
+	searchHandler *handler.SearchHandler,
+	taskDependencyHandler *handler.TaskDependencyHandler,
+	workflowHandler *handler.WorkflowHandler,
+	commentHandler *handler.CommentHandler,
+	notificationHandler *handler.NotificationHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	taskActivityHandler *handler.TaskActivityHandler,
+	taskRecurrenceHandler *handler.TaskRecurrenceHandler,
+	boardHandler *handler.BoardHandler,
+	timeTrackingHandler *handler.TimeTrackingHandler,
+	labelHandler *handler.LabelHandler,
 ) {
 	r.Use(middleware.RateLimitMiddleware())
 	// CORS is removed from middleware.go but normally should be here.
@@ -35,18 +51,111 @@ func NewRouter(
 		{
 			projects.POST("", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), projectHandler.Create)
 			projects.GET("", projectHandler.GetAll)
+			projects.GET("/cache-metrics", middleware.AuthMiddleware(domain.RoleAdmin), projectHandler.GetCacheMetrics)
+			projects.PUT("/favorites/order", projectHandler.ReorderFavorites)
+			projects.POST("/import", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), projectBundleHandler.Import)
 			projects.GET("/:id", projectHandler.GetByID)
+			projects.GET("/:id/stats", projectHandler.GetStats)
+			projects.GET("/:id/timeline", timelineHandler.GetProjectTimeline)
+			projects.GET("/:id/burndown", reportHandler.GetBurndown)
+			projects.GET("/:id/cumulative-flow", reportHandler.GetCumulativeFlow)
+			projects.GET("/:id/activity", taskActivityHandler.GetByProjectID)
+			projects.GET("/:id/export", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), projectBundleHandler.Export)
 			projects.PUT("/:id", projectHandler.Update) // Add ownership check in usecase or here? Middleware checks role/auth.
 			projects.DELETE("/:id", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), projectHandler.Delete)
+			projects.POST("/:id/clone", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), projectHandler.Clone)
+			projects.POST("/:id/template", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), projectHandler.SaveAsTemplate)
+			projects.POST("/:id/transfer", projectHandler.TransferOwnership) // Owner or admin, checked in usecase
+			projects.PUT("/:id/settings", projectHandler.UpdateSettings)     // Owner or admin, checked in usecase
+			projects.GET("/:id/workflow", workflowHandler.GetWorkflow)
+			projects.PUT("/:id/workflow", workflowHandler.UpdateWorkflow)   // Owner or admin, checked in usecase
+			projects.DELETE("/:id/workflow", workflowHandler.ResetWorkflow) // Owner or admin, checked in usecase
+			projects.GET("/:id/board", boardHandler.GetBoard)
+			projects.PUT("/:id/board/limits", boardHandler.SetWIPLimits) // Owner or admin, checked in usecase
+			projects.PUT("/:id/favorite", projectHandler.SetFavorite)
+			projects.DELETE("/:id/favorite", projectHandler.RemoveFavorite)
+
+			sprints := projects.Group("/:id/sprints")
+			{
+				manage := middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager)
+				sprints.GET("", sprintHandler.GetByProjectID)
+				sprints.POST("", manage, sprintHandler.Create)
+				sprints.GET("/:sprint_id", sprintHandler.GetByID)
+				sprints.PUT("/:sprint_id", manage, sprintHandler.Update)
+				sprints.DELETE("/:sprint_id", manage, sprintHandler.Delete)
+				sprints.POST("/:sprint_id/start", manage, sprintHandler.Start)
+				sprints.POST("/:sprint_id/complete", manage, sprintHandler.Complete)
+				sprints.POST("/:sprint_id/tasks", manage, sprintHandler.AssignTasks)
+				sprints.DELETE("/:sprint_id/tasks/:task_id", manage, sprintHandler.RemoveTask)
+			}
+
+			// Ownership is checked in the usecase
+			projects.GET("/:id/custom-fields", customFieldHandler.GetByProjectID)
+			projects.POST("/:id/custom-fields", customFieldHandler.Create)
+			projects.PUT("/:id/custom-fields/:field_id", customFieldHandler.Update)
+			projects.DELETE("/:id/custom-fields/:field_id", customFieldHandler.Delete)
+			projects.GET("/:id/labels", labelHandler.GetByProjectID)
+			projects.POST("/:id/labels", labelHandler.Create)
+			projects.PUT("/:id/labels/:label_id", labelHandler.Update)
+			projects.DELETE("/:id/labels/:label_id", labelHandler.Delete)
+			projects.POST("/:id/labels/:label_id/merge", labelHandler.Merge)
+		}
+
+		api.GET("/search", middleware.AuthMiddleware(), searchHandler.Search)
+
+		notifications := api.Group("/notifications")
+		notifications.Use(middleware.AuthMiddleware())
+		{
+			notifications.GET("", notificationHandler.List)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
 		}
 
+		// The timer belongs to the authenticated user
+		timer := api.Group("/timer")
+		timer.Use(middleware.AuthMiddleware())
+		{
+			timer.GET("", timeTrackingHandler.GetTimer)
+			timer.POST("/start", timeTrackingHandler.StartTimer)
+			timer.POST("/stop", timeTrackingHandler.StopTimer)
+		}
+
+		api.GET("/reports/time", middleware.AuthMiddleware(), timeTrackingHandler.GetReport) // Members only see their own time, enforced in usecase
+
 		tasks := api.Group("/tasks")
 		tasks.Use(middleware.AuthMiddleware())
 		{
 			tasks.POST("", taskHandler.Create)
+			tasks.POST("/bulk", taskHandler.Bulk) // Move and delete need an admin or manager, checked in usecase
 			tasks.GET("/project/:project_id", taskHandler.GetByProjectID)
 			tasks.GET("/assignee/:assignee_id", taskHandler.GetByAssigneeID) // New route
+			tasks.GET("/:id/subtree", taskHandler.GetSubtree)
+			tasks.GET("/:id/activity", taskActivityHandler.GetByTaskID)
+			tasks.GET("/:id/recurrence", taskRecurrenceHandler.GetRecurrence)
+			tasks.PUT("/:id/recurrence", taskRecurrenceHandler.SetRecurrence)
+			tasks.POST("/:id/recurrence/skip", taskRecurrenceHandler.Skip)
+			tasks.DELETE("/:id/recurrence", taskRecurrenceHandler.Stop)
+			tasks.GET("/:id/dependencies", taskDependencyHandler.GetDependencies)
+			tasks.POST("/:id/blockers", taskDependencyHandler.AddBlocker)
+			tasks.DELETE("/:id/blockers/:blocker_id", taskDependencyHandler.RemoveBlocker)
+			tasks.GET("/:id/comments", commentHandler.GetByTaskID)
+			tasks.POST("/:id/comments", commentHandler.Create)
+			tasks.PUT("/:id/comments/:comment_id", commentHandler.Update)    // Author only, checked in usecase
+			tasks.DELETE("/:id/comments/:comment_id", commentHandler.Delete) // Author, admin or manager, checked in usecase
+			tasks.GET("/:id/comments/:comment_id/history", commentHandler.GetHistory)
+			tasks.GET("/:id/attachments", attachmentHandler.GetByTaskID)
+			tasks.POST("/:id/attachments", attachmentHandler.Upload)
+			tasks.GET("/:id/attachments/:attachment_id", attachmentHandler.Download)
+			tasks.DELETE("/:id/attachments/:attachment_id", attachmentHandler.Delete) // Uploader, admin or manager, checked in usecase
+			tasks.GET("/:id/time-entries", timeTrackingHandler.GetEntries)
+			tasks.POST("/:id/time-entries", timeTrackingHandler.LogTime)
+			tasks.PUT("/:id/time-entries/:entry_id", timeTrackingHandler.UpdateEntry)    // Author only, checked in usecase
+			tasks.DELETE("/:id/time-entries/:entry_id", timeTrackingHandler.DeleteEntry) // Author, admin or manager, checked in usecase
 			tasks.PUT("/:id", taskHandler.Update)
+			tasks.POST("/:id/move", taskHandler.Move)
+			tasks.POST("/:id/watchers", taskHandler.Watch)              // Members can only watch for themselves, checked in usecase
+			tasks.DELETE("/:id/watchers/:user_id", taskHandler.Unwatch) // Members can only remove themselves, checked in usecase
+			tasks.PUT("/:id/custom-fields", customFieldHandler.SetTaskValues)
 			tasks.DELETE("/:id", middleware.AuthMiddleware(domain.RoleAdmin, domain.RoleManager), taskHandler.Delete)
 		}
 	}
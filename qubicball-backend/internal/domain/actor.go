@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+// Actor is the authenticated user a request is made on behalf of.
+type Actor struct {
+	UserID uint
+	Role   Role
+}
+
+type actorKey struct{}
+
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the request's actor. Background jobs have none.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// CanManage reports whether the actor may change the project's settings.
+func (a Actor) CanManage(project *Project) bool {
+	return a.Role == RoleAdmin || a.UserID == project.OwnerID
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// MaxAttachmentSize is the largest file that can be attached to a task.
+const MaxAttachmentSize = 25 << 20
+
+// ErrBlobNotFound is returned by a BlobStore for a key it doesn't hold.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Attachment is a file attached to a task. Files are stored once per
+// content, so attachments with the same checksum share a blob.
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TaskID      uint      `gorm:"not null;index" json:"task_id"`
+	UploaderID  uint      `gorm:"not null" json:"uploader_id"`
+	Uploader    User      `gorm:"foreignKey:UploaderID" json:"uploader"`
+	FileName    string    `gorm:"not null" json:"file_name"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	Checksum    string    `gorm:"type:char(64);not null;index" json:"checksum"` // Hex SHA-256 of the content
+	StorageKey  string    `gorm:"not null;index" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentUpload is a file as received from the client. Content is read
+// twice, once to checksum it and once to store it.
+type AttachmentUpload struct {
+	FileName    string
+	ContentType string // As declared by the client
+	Size        int64
+	Content     io.ReadSeeker
+}
+
+// BlobStore keeps attachment content. Keys are chosen by the caller and Put
+// overwrites.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *Attachment) error
+	GetByID(ctx context.Context, id uint) (*Attachment, error)
+	GetByTaskID(ctx context.Context, taskID uint) ([]Attachment, error)
+	GetByTaskAndChecksum(ctx context.Context, taskID uint, checksum string) (*Attachment, error)
+	Delete(ctx context.Context, id uint) error
+	// DeleteByTaskIDs removes the tasks' attachments and returns the storage
+	// keys they used.
+	DeleteByTaskIDs(ctx context.Context, taskIDs []uint) ([]string, error)
+	CountByStorageKey(ctx context.Context, key string) (int64, error)
+	// LockBlob serializes writers and releasers of one blob until the
+	// transaction ends.
+	LockBlob(ctx context.Context, key string) error
+}
+
+type AttachmentUsecase interface {
+	// Upload attaches the file as the request's actor. Uploading a file the
+	// task already has returns the existing attachment with created false.
+	Upload(ctx context.Context, taskID uint, upload AttachmentUpload) (attachment *Attachment, created bool, err error)
+	GetByTaskID(ctx context.Context, taskID uint) ([]Attachment, error)
+	// Open returns the attachment and its content, which the caller closes.
+	Open(ctx context.Context, taskID, id uint) (*Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, taskID, id uint) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditLog records a privileged change for later review.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorID    *uint     `gorm:"index" json:"actor_id"` // nil for system jobs
+	Action     string    `gorm:"type:varchar(50);not null" json:"action"`
+	EntityType string    `gorm:"type:varchar(50);not null;index:idx_audit_entity" json:"entity_type"`
+	EntityID   uint      `gorm:"not null;index:idx_audit_entity" json:"entity_id"`
+	Details    JSONValue `gorm:"type:jsonb" json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+const AuditActionProjectOwnerTransferred = "project.owner_transferred"
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *AuditLog) error
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// WIPLimits caps how many tasks each board column may hold, keyed by
+// status. Statuses without a limit are unbounded.
+type WIPLimits map[TaskStatus]int
+
+func (l WIPLimits) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	return string(b), err
+}
+
+func (l *WIPLimits) Scan(src interface{}) error {
+	return scanJSON(src, l)
+}
+
+// Validate checks the limits against the workflow they apply to.
+func (l WIPLimits) Validate(workflow *Workflow) error {
+	for status, limit := range l {
+		if err := workflow.CheckStatus(status); err != nil {
+			return err
+		}
+		if limit < 1 {
+			return fmt.Errorf("%w: the WIP limit for %q must be at least 1", ErrInvalidInput, status)
+		}
+	}
+	return nil
+}
+
+// Limit returns the column's limit, nil when it has none.
+func (l WIPLimits) Limit(status TaskStatus) *int {
+	if limit, ok := l[status]; ok {
+		return &limit
+	}
+	return nil
+}
+
+// Prune returns the limits whose statuses are still in the workflow.
+func (l WIPLimits) Prune(workflow *Workflow) WIPLimits {
+	var pruned WIPLimits
+	for status, limit := range l {
+		if workflow.Has(status) {
+			if pruned == nil {
+				pruned = WIPLimits{}
+			}
+			pruned[status] = limit
+		}
+	}
+	return pruned
+}
+
+// BoardColumn holds the cards of one workflow status in their manual order.
+// Count covers every task in the column, including cards the viewer cannot
+// see, since WIP limits apply to the whole team.
+type BoardColumn struct {
+	Status    TaskStatus `json:"status"`
+	WIPLimit  *int       `json:"wip_limit"`
+	Count     int64      `json:"count"`
+	OverLimit bool       `json:"over_limit"`
+	Cards     []Task     `json:"cards"`
+}
+
+// Board is a project's tasks grouped into one column per workflow status.
+type Board struct {
+	ProjectID uint          `json:"project_id"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+type BoardUsecase interface {
+	// GetBoard returns the project's board. With assigneeID set, only that
+	// user's tasks appear as cards.
+	GetBoard(ctx context.Context, projectID uint, assigneeID *uint) (*Board, error)
+	// SetWIPLimits replaces the project's limits. Only the owner or an admin
+	// may change them.
+	SetWIPLimits(ctx context.Context, projectID uint, limits WIPLimits) (WIPLimits, error)
+}
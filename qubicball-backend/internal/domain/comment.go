@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a markdown note on a task. Body keeps the source as written;
+// BodyHTML is rendered and sanitized when the comment is read.
+type Comment struct {
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	TaskID    uint             `gorm:"not null;index" json:"task_id"`
+	Task      Task             `gorm:"foreignKey:TaskID" json:"-"`
+	AuthorID  uint             `gorm:"not null" json:"author_id"`
+	Author    User             `gorm:"foreignKey:AuthorID" json:"author"`
+	Body      string           `gorm:"type:text;not null" json:"body"`
+	BodyHTML  string           `gorm:"-" json:"body_html"`
+	Mentions  []CommentMention `gorm:"foreignKey:CommentID" json:"mentions"`
+	EditedAt  *time.Time       `json:"edited_at"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	DeletedAt gorm.DeletedAt   `gorm:"index" json:"-"`
+}
+
+// CommentMention is a user resolved from an @handle in a comment.
+type CommentMention struct {
+	CommentID uint   `gorm:"primaryKey" json:"-"`
+	UserID    uint   `gorm:"primaryKey;index" json:"user_id"`
+	Handle    string `gorm:"not null" json:"handle"` // As written, without the @
+}
+
+// CommentRevision keeps a comment's body as it was before an edit.
+type CommentRevision struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CommentID  uint      `gorm:"not null;index" json:"comment_id"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	EditedByID uint      `gorm:"not null" json:"edited_by_id"`
+	CreatedAt  time.Time `json:"created_at"` // When this body was replaced
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *Comment) error
+	GetByID(ctx context.Context, id uint) (*Comment, error)
+	GetByTaskID(ctx context.Context, taskID uint) ([]Comment, error)
+	// Update saves the comment's body and edit time and replaces its
+	// mentions.
+	Update(ctx context.Context, comment *Comment) error
+	Delete(ctx context.Context, id uint) error
+	CreateRevision(ctx context.Context, revision *CommentRevision) error
+	GetRevisions(ctx context.Context, commentID uint) ([]CommentRevision, error)
+}
+
+type CommentUsecase interface {
+	// Comments are written as the request's actor.
+	Create(ctx context.Context, taskID uint, body string) (*Comment, error)
+	GetByTaskID(ctx context.Context, taskID uint) ([]Comment, error)
+	Update(ctx context.Context, taskID, id uint, body string) (*Comment, error)
+	Delete(ctx context.Context, taskID, id uint) error
+	GetHistory(ctx context.Context, taskID, id uint) ([]CommentRevision, error)
+}
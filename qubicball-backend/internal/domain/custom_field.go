@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText         CustomFieldType = "text"
+	CustomFieldNumber       CustomFieldType = "number"
+	CustomFieldDate         CustomFieldType = "date"
+	CustomFieldSingleSelect CustomFieldType = "single_select"
+	CustomFieldMultiSelect  CustomFieldType = "multi_select"
+	CustomFieldUser         CustomFieldType = "user"
+)
+
+func (t CustomFieldType) Valid() bool {
+	switch t {
+	case CustomFieldText, CustomFieldNumber, CustomFieldDate,
+		CustomFieldSingleSelect, CustomFieldMultiSelect, CustomFieldUser:
+		return true
+	}
+	return false
+}
+
+// StringList is a []string stored as a JSON array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	return scanJSON(src, l)
+}
+
+// JSONValue is raw JSON stored in a jsonb column.
+type JSONValue json.RawMessage
+
+func (v JSONValue) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return string(v), nil
+}
+
+func (v *JSONValue) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		*v = nil
+	case []byte:
+		*v = append(JSONValue(nil), data...)
+	case string:
+		*v = JSONValue(data)
+	default:
+		return fmt.Errorf("unsupported JSON source %T", src)
+	}
+	return nil
+}
+
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+func (v *JSONValue) UnmarshalJSON(data []byte) error {
+	*v = append(JSONValue(nil), data...)
+	return nil
+}
+
+func scanJSON(src interface{}, dst interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(data, dst)
+	case string:
+		return json.Unmarshal([]byte(data), dst)
+	default:
+		return fmt.Errorf("unsupported JSON source %T", src)
+	}
+}
+
+// CustomField is a typed task attribute defined per project. New tasks must
+// be given a value for every required field, and it can only be replaced,
+// not cleared. Tasks that predate a field being made required keep going
+// without one.
+type CustomField struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	ProjectID uint            `gorm:"not null;index" json:"project_id"`
+	Project   Project         `gorm:"foreignKey:ProjectID" json:"-"`
+	Name      string          `gorm:"not null" json:"name"`
+	Type      CustomFieldType `gorm:"type:varchar(20);not null" json:"type"`
+	Options   StringList      `gorm:"type:text" json:"options,omitempty"` // choices for select types
+	Required  bool            `json:"required"`
+	Position  int             `json:"position"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	DeletedAt gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// CustomFieldValue is a task's value for one custom field.
+type CustomFieldValue struct {
+	TaskID    uint      `gorm:"primaryKey" json:"-"`
+	FieldID   uint      `gorm:"primaryKey" json:"field_id"`
+	Value     JSONValue `gorm:"type:jsonb;not null" json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CustomFieldFilter matches tasks whose value for a field equals Value. For
+// multi-select fields it matches tasks that have Value among their choices.
+type CustomFieldFilter struct {
+	FieldID uint
+	Type    CustomFieldType
+	Value   string
+}
+
+// CustomFieldChanges edits a custom field. An empty Name and nil Options,
+// Required and Position keep the current value. A field's type can't
+// change, so Type may only repeat it.
+type CustomFieldChanges struct {
+	Name     string
+	Type     CustomFieldType
+	Options  StringList
+	Required *bool
+	Position *int
+}
+
+type CustomFieldRepository interface {
+	Create(ctx context.Context, field *CustomField) error
+	GetByID(ctx context.Context, id uint) (*CustomField, error)
+	GetByProjectID(ctx context.Context, projectID uint) ([]CustomField, error)
+	Update(ctx context.Context, field *CustomField) error
+	Delete(ctx context.Context, id uint) error
+	GetValues(ctx context.Context, taskID uint) ([]CustomFieldValue, error)
+	UpsertValues(ctx context.Context, values []CustomFieldValue) error
+	DeleteValues(ctx context.Context, taskID uint, fieldIDs []uint) error
+	DeleteValuesByField(ctx context.Context, fieldID uint) error
+}
+
+type CustomFieldUsecase interface {
+	Create(ctx context.Context, field *CustomField) error
+	GetByProjectID(ctx context.Context, projectID uint) ([]CustomField, error)
+	Update(ctx context.Context, projectID, id uint, changes CustomFieldChanges) (*CustomField, error)
+	Delete(ctx context.Context, projectID, id uint) error
+	// SetTaskValues validates and stores values keyed by field ID. A JSON
+	// null clears the value.
+	SetTaskValues(ctx context.Context, taskID uint, values map[uint]json.RawMessage) ([]CustomFieldValue, error)
+}
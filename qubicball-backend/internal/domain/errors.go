@@ -0,0 +1,11 @@
+package domain
+
+import "errors"
+
+// Sentinel errors usecases wrap with context so handlers can pick a status
+// code with errors.Is.
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+)
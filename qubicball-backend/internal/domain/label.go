@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Label tags tasks within one project. Names are unique per project,
+// ignoring case, and tasks refer to labels by ID, so renaming a label
+// renames it on every task.
+type Label struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProjectID uint      `gorm:"not null;index" json:"project_id"`
+	Name      string    `gorm:"type:varchar(50);not null" json:"name"`
+	Color     string    `gorm:"type:varchar(7);not null" json:"color"` // #rrggbb
+	TaskCount int64     `gorm:"->;-:migration" json:"task_count"`      // Filled by project listings
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskLabel is the join row behind Task.Labels.
+type TaskLabel struct {
+	TaskID    uint `gorm:"primaryKey"`
+	LabelID   uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+type LabelRepository interface {
+	Create(ctx context.Context, label *Label) error
+	GetByID(ctx context.Context, id uint) (*Label, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]Label, error)
+	// GetByProjectID lists the project's labels by name with their task
+	// counts.
+	GetByProjectID(ctx context.Context, projectID uint) ([]Label, error)
+	// GetByName finds a project's label by name, ignoring case.
+	GetByName(ctx context.Context, projectID uint, name string) (*Label, error)
+	Update(ctx context.Context, label *Label) error
+	// Delete removes the label from its tasks and then deletes it.
+	Delete(ctx context.Context, id uint) error
+	// Merge moves every task labelled sourceID onto targetID and deletes
+	// the source label.
+	Merge(ctx context.Context, sourceID, targetID uint) error
+}
+
+type LabelUsecase interface {
+	Create(ctx context.Context, label *Label) error
+	GetByProjectID(ctx context.Context, projectID uint) ([]Label, error)
+	// Update renames or recolors a label; empty fields are kept.
+	Update(ctx context.Context, label *Label) error
+	Delete(ctx context.Context, projectID, id uint) error
+	// Merge folds the source label into the target, both of the project,
+	// and returns the target.
+	Merge(ctx context.Context, projectID, sourceID, targetID uint) (*Label, error)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type NotificationType string
+
+const (
+	NotificationMention NotificationType = "mention"
+	NotificationComment NotificationType = "comment" // New comment on a task the user is assigned to or watches
+)
+
+// Notification tells a user about something that involves them.
+type Notification struct {
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	UserID    uint             `gorm:"not null;index" json:"user_id"`
+	Type      NotificationType `gorm:"type:varchar(30);not null" json:"type"`
+	ActorID   *uint            `json:"actor_id"`
+	TaskID    *uint            `json:"task_id"`
+	CommentID *uint            `json:"comment_id"`
+	Message   string           `gorm:"not null" json:"message"`
+	ReadAt    *time.Time       `json:"read_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type NotificationRepository interface {
+	CreateBatch(ctx context.Context, notifications []Notification) error
+	GetByUserID(ctx context.Context, userID uint, unreadOnly bool, limit int) ([]Notification, error)
+	MarkRead(ctx context.Context, userID, id uint) error
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+}
+
+// NotificationUsecase works on the request's actor's notifications.
+type NotificationUsecase interface {
+	List(ctx context.Context, unreadOnly bool) ([]Notification, error)
+	MarkRead(ctx context.Context, id uint) error
+	MarkAllRead(ctx context.Context) (int64, error)
+}
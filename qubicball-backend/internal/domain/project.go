@@ -2,35 +2,149 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Project struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Name        string         `gorm:"not null" json:"name"`
-	Description string         `json:"description"`
-	OwnerID     uint           `gorm:"not null" json:"owner_id"`
-	Owner       User           `gorm:"foreignKey:OwnerID" json:"owner"`
-	Version     int            `gorm:"default:1" json:"version"` // Optimistic Locking
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	Name        string          `gorm:"not null" json:"name"`
+	Description string          `json:"description"`
+	OwnerID     uint            `gorm:"not null" json:"owner_id"`
+	Owner       User            `gorm:"foreignKey:OwnerID" json:"owner"`
+	IsTemplate  bool            `gorm:"not null;default:false;index" json:"is_template"`
+	Settings    ProjectSettings `gorm:"embedded;embeddedPrefix:setting_" json:"settings"`
+	Workflow    *Workflow       `gorm:"type:jsonb" json:"workflow"`        // nil uses DefaultWorkflow
+	WIPLimits   WIPLimits       `gorm:"type:jsonb" json:"wip_limits"`      // Board column limits by status
+	IsFavorite  bool            `gorm:"->;-:migration" json:"is_favorite"` // Per-user, filled by GetAll
+	IsPinned    bool            `gorm:"->;-:migration" json:"is_pinned"`   // Per-user, filled by GetAll
+	Version     int             `gorm:"default:1" json:"version"`          // Optimistic Locking
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// EffectiveWorkflow returns the workflow tasks in the project follow.
+func (p *Project) EffectiveWorkflow() *Workflow {
+	if p.Workflow != nil {
+		return p.Workflow
+	}
+	return DefaultWorkflow()
+}
+
+// ProjectSettings are per-project rules enforced on tasks. Zero values keep
+// the default behaviour.
+type ProjectSettings struct {
+	// BlockParentCompletion refuses to complete a task while any of its
+	// subtasks are open.
+	BlockParentCompletion bool `gorm:"not null;default:false" json:"block_parent_completion"`
+	// AllowOpenBlockers lets tasks be completed while tasks blocking them
+	// are still open.
+	AllowOpenBlockers bool `gorm:"not null;default:false" json:"allow_open_blockers"`
+}
+
+// ProjectFavorite marks a project as starred by a user. Pinned favorites sort
+// ahead of the rest; Position is the user's own ordering.
+type ProjectFavorite struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	ProjectID uint      `gorm:"primaryKey;index" json:"project_id"`
+	Project   Project   `gorm:"foreignKey:ProjectID" json:"-"`
+	Pinned    bool      `gorm:"not null;default:false" json:"pinned"`
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProjectFilter narrows project listings.
+type ProjectFilter struct {
+	Templates bool // list templates instead of regular projects
+	// UserID personalises the listing: the user's pinned projects come
+	// first, then their other favorites, then everything else.
+	UserID        uint
+	FavoritesOnly bool
+}
+
+// CacheKey encodes the filter for use in list cache keys.
+func (f ProjectFilter) CacheKey() string {
+	return fmt.Sprintf("t=%t:u=%d:f=%t", f.Templates, f.UserID, f.FavoritesOnly)
+}
+
+// CacheMetrics counts cache lookups since the process started.
+type CacheMetrics struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// ProjectCloneOptions controls how a project and its tasks are copied.
+type ProjectCloneOptions struct {
+	Name        string
+	Description *string
+	OwnerID     uint
+	AsTemplate  bool
+	// StartDate re-anchors due dates: the earliest source due date maps to
+	// StartDate and every other task keeps its offset from it.
+	StartDate *time.Time
+	// AssigneeMap remaps source assignees; a target of 0 drops the
+	// assignee. Assignees not in the map are kept.
+	AssigneeMap map[uint]uint
+}
+
+// ProjectStats summarises task progress within a project.
+type ProjectStats struct {
+	ProjectID         uint                 `json:"project_id"`
+	Total             int64                `json:"total"`
+	ByStatus          map[TaskStatus]int64 `json:"by_status"`
+	Overdue           int64                `json:"overdue"`
+	CompletionPercent float64              `json:"completion_percent"`
+	ByAssignee        []AssigneeStats      `json:"by_assignee"`
+	DueSoonDays       int                  `json:"due_soon_days"`
+	DueSoon           []Task               `json:"due_soon"`
+	GeneratedAt       time.Time            `json:"generated_at"`
+}
+
+type AssigneeStats struct {
+	AssigneeID *uint  `json:"assignee_id"`
+	Name       string `json:"name"`
+	Total      int64  `json:"total"`
+	Completed  int64  `json:"completed"`
+	Overdue    int64  `json:"overdue"`
 }
 
 type ProjectRepository interface {
 	Create(ctx context.Context, project *Project) error
 	GetByID(ctx context.Context, id uint) (*Project, error)
-	GetAll(ctx context.Context, limit, offset int) ([]Project, error)
+	GetAll(ctx context.Context, filter ProjectFilter, limit, offset int) ([]Project, error)
 	Update(ctx context.Context, project *Project) error
+	UpdateOwner(ctx context.Context, id uint, version int, ownerID uint) error
+	UpdateSettings(ctx context.Context, id uint, settings ProjectSettings) error
+	UpdateWorkflow(ctx context.Context, id uint, workflow *Workflow) error
+	UpdateWIPLimits(ctx context.Context, id uint, limits WIPLimits) error
 	Delete(ctx context.Context, id uint) error
+	SaveFavorite(ctx context.Context, favorite *ProjectFavorite) error
+	GetFavorite(ctx context.Context, userID, projectID uint) (*ProjectFavorite, error)
+	GetFavorites(ctx context.Context, userID uint) ([]ProjectFavorite, error)
+	DeleteFavorite(ctx context.Context, userID, projectID uint) error
+	SetFavoritePositions(ctx context.Context, userID uint, projectIDs []uint) error
 }
 
 type ProjectUsecase interface {
 	Create(ctx context.Context, project *Project) error
 	GetByID(ctx context.Context, id uint) (*Project, error)
-	GetAll(ctx context.Context, page, pageSize int) ([]Project, error)
+	GetAll(ctx context.Context, filter ProjectFilter, page, pageSize int) ([]Project, error)
 	Update(ctx context.Context, project *Project) error
 	Delete(ctx context.Context, id uint) error
+	Clone(ctx context.Context, sourceID uint, opts ProjectCloneOptions) (*Project, error)
+	GetStats(ctx context.Context, id uint, dueSoonDays int) (*ProjectStats, error)
+	// TransferOwnership hands the project to newOwnerID. version must match
+	// the project's current version.
+	TransferOwnership(ctx context.Context, id uint, newOwnerID uint, version int) (*Project, error)
+	UpdateSettings(ctx context.Context, id uint, settings ProjectSettings) (*Project, error)
+	ListCacheMetrics() CacheMetrics
+	// Favorites belong to the request's actor.
+	SetFavorite(ctx context.Context, projectID uint, pinned bool) (*ProjectFavorite, error)
+	RemoveFavorite(ctx context.Context, projectID uint) error
+	ReorderFavorites(ctx context.Context, projectIDs []uint) error
 }
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ProjectBundleFormatVersion is the bundle layout written by Export. Import
+// accepts bundles up to this version.
+const ProjectBundleFormatVersion = 1
+
+// ProjectBundle is a portable snapshot of a project. Users are referenced by
+// email, labels by name and sprints and custom fields by bundle-local refs,
+// so the bundle can be imported into a deployment with different IDs.
+type ProjectBundle struct {
+	FormatVersion int                 `json:"format_version"`
+	ExportedAt    time.Time           `json:"exported_at"`
+	Metadata      BundleMetadata      `json:"metadata"`
+	Project       BundleProject       `json:"project"`
+	CustomFields  []BundleCustomField `json:"custom_fields"`
+	Labels        []BundleLabel       `json:"labels,omitempty"`
+	Sprints       []BundleSprint      `json:"sprints"`
+	Tasks         []BundleTask        `json:"tasks"`
+}
+
+type BundleMetadata struct {
+	SourceProjectID uint   `json:"source_project_id"`
+	ExportedBy      string `json:"exported_by,omitempty"`
+	TaskCount       int    `json:"task_count"`
+}
+
+type BundleProject struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	OwnerEmail  string          `json:"owner_email"`
+	IsTemplate  bool            `json:"is_template"`
+	Settings    ProjectSettings `json:"settings"`
+	Workflow    *Workflow       `json:"workflow,omitempty"` // Omitted for the default workflow
+	WIPLimits   WIPLimits       `json:"wip_limits,omitempty"`
+}
+
+type BundleCustomField struct {
+	Ref      uint            `json:"ref"`
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Options  []string        `json:"options,omitempty"`
+	Required bool            `json:"required"`
+	Position int             `json:"position"`
+}
+
+type BundleLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type BundleSprint struct {
+	Ref       uint         `json:"ref"`
+	Name      string       `json:"name"`
+	Goal      string       `json:"goal"`
+	StartDate time.Time    `json:"start_date"`
+	EndDate   time.Time    `json:"end_date"`
+	Status    SprintStatus `json:"status"`
+}
+
+type BundleTask struct {
+	Title           string       `json:"title"`
+	Description     string       `json:"description"`
+	Status          TaskStatus   `json:"status"`
+	Priority        TaskPriority `json:"priority,omitempty"`
+	DueDate         time.Time    `json:"due_date"`
+	StartDate       *time.Time   `json:"start_date,omitempty"`
+	DurationDays    *int         `json:"duration_days,omitempty"`
+	EstimateMinutes *int         `json:"estimate_minutes,omitempty"` // Imported tasks start with it all remaining
+	AssigneeEmail   string       `json:"assignee_email,omitempty"`   // Primary assignee
+	// AssigneeEmails lists the other assignees.
+	AssigneeEmails []string           `json:"assignee_emails,omitempty"`
+	SprintRef      *uint              `json:"sprint_ref,omitempty"`
+	CustomFields   []BundleFieldValue `json:"custom_fields,omitempty"`
+	Labels         []string           `json:"labels,omitempty"` // Label names
+}
+
+// BundleFieldValue holds a task's custom field value. Values of user fields
+// are the user's email.
+type BundleFieldValue struct {
+	FieldRef uint      `json:"field_ref"`
+	Value    JSONValue `json:"value"`
+}
+
+// UnresolvedReference is a bundle reference Import could not map, and what
+// it did instead.
+type UnresolvedReference struct {
+	Kind     string `json:"kind"` // "user", "sprint", "custom_field", "custom_field_value", "label", "status", "priority" or "estimate_minutes"
+	Value    string `json:"value"`
+	Location string `json:"location"`
+	Action   string `json:"action"`
+}
+
+type ProjectImportReport struct {
+	DryRun       bool                  `json:"dry_run"`
+	Project      *Project              `json:"project,omitempty"` // nil on dry runs
+	Tasks        int                   `json:"tasks"`
+	Sprints      int                   `json:"sprints"`
+	CustomFields int                   `json:"custom_fields"`
+	Labels       int                   `json:"labels"`
+	Unresolved   []UnresolvedReference `json:"unresolved"`
+}
+
+type ProjectBundleUsecase interface {
+	Export(ctx context.Context, projectID uint) (*ProjectBundle, error)
+	// Import recreates the bundle as a new project owned by the bundle's
+	// owner when they exist here, otherwise by the request's actor. With
+	// dryRun nothing is written.
+	Import(ctx context.Context, bundle *ProjectBundle, dryRun bool) (*ProjectImportReport, error)
+}
@@ -0,0 +1,396 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+	RecurrenceYearly  RecurrenceFrequency = "YEARLY"
+)
+
+// maxRecurrencePeriods bounds the search for the next occurrence, so rules
+// that can never match (e.g. the 31st of every other February) end instead
+// of looping.
+const maxRecurrencePeriods = 1000
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// RecurrenceWeekday is a BYDAY entry. Ordinal picks the nth such weekday of
+// the month, counting from the end when negative; 0 means every one.
+type RecurrenceWeekday struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// RecurrenceRule is the supported subset of an RFC 5545 RRULE: FREQ,
+// INTERVAL, COUNT, UNTIL, BYDAY and BYMONTHDAY. Weeks start on Monday and
+// occurrences keep the time of day of the series start.
+type RecurrenceRule struct {
+	Freq       RecurrenceFrequency
+	Interval   int
+	Count      int        // 0 for no limit; counts the first occurrence
+	Until      *time.Time // Last possible occurrence
+	ByDay      []RecurrenceWeekday
+	ByMonthDay []int
+}
+
+// ParseRecurrenceRule parses an RRULE value, with or without the "RRULE:"
+// prefix.
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: recurrence rule is required", ErrInvalidInput)
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, raw, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		raw = strings.ToUpper(strings.TrimSpace(raw))
+		if !ok || raw == "" {
+			return nil, fmt.Errorf("%w: malformed recurrence rule part %q", ErrInvalidInput, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: recurrence rule repeats %s", ErrInvalidInput, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = RecurrenceFrequency(raw)
+			switch rule.Freq {
+			case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly:
+			default:
+				err = fmt.Errorf("unsupported FREQ %s", raw)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(raw)
+		case "COUNT":
+			rule.Count, err = parsePositive(raw)
+		case "UNTIL":
+			var until time.Time
+			until, err = parseRecurrenceUntil(raw)
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(raw, ",") {
+				var weekday RecurrenceWeekday
+				if weekday, err = parseRecurrenceWeekday(day); err != nil {
+					break
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(raw, ",") {
+				var n int
+				n, err = strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					err = fmt.Errorf("invalid BYMONTHDAY %s", day)
+					break
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			if raw != "MO" {
+				err = fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			err = fmt.Errorf("%s is not supported", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: recurrence rule: %v", ErrInvalidInput, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: recurrence rule needs a FREQ", ErrInvalidInput)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: recurrence rule cannot have both COUNT and UNTIL", ErrInvalidInput)
+	}
+	switch rule.Freq {
+	case RecurrenceDaily, RecurrenceYearly:
+		if len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("%w: BYDAY and BYMONTHDAY are supported with WEEKLY and MONTHLY only", ErrInvalidInput)
+		}
+	case RecurrenceWeekly:
+		if len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("%w: BYMONTHDAY cannot be used with WEEKLY", ErrInvalidInput)
+		}
+		for _, day := range rule.ByDay {
+			if day.Ordinal != 0 {
+				return nil, fmt.Errorf("%w: numbered BYDAY entries need MONTHLY", ErrInvalidInput)
+			}
+		}
+	case RecurrenceMonthly:
+		if len(rule.ByDay) > 0 && len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("%w: use either BYDAY or BYMONTHDAY", ErrInvalidInput)
+		}
+	}
+	return rule, nil
+}
+
+func parsePositive(raw string) (int, error) {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive number", raw)
+	}
+	return n, nil
+}
+
+func parseRecurrenceUntil(raw string) (time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", raw); err == nil {
+		return until, nil
+	}
+	until, err := time.Parse("20060102", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid UNTIL %s", raw)
+	}
+	// A date-only UNTIL includes the whole day
+	return until.Add(24*time.Hour - time.Second), nil
+}
+
+func parseRecurrenceWeekday(raw string) (RecurrenceWeekday, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 2 {
+		return RecurrenceWeekday{}, fmt.Errorf("invalid BYDAY %s", raw)
+	}
+	day, ok := recurrenceWeekdays[raw[len(raw)-2:]]
+	if !ok {
+		return RecurrenceWeekday{}, fmt.Errorf("invalid BYDAY %s", raw)
+	}
+	weekday := RecurrenceWeekday{Day: day}
+	if prefix := raw[:len(raw)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RecurrenceWeekday{}, fmt.Errorf("invalid BYDAY %s", raw)
+		}
+		weekday.Ordinal = n
+	}
+	return weekday, nil
+}
+
+// String formats the rule in canonical RRULE form, without the prefix.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			name := strings.ToUpper(day.Day.String()[:2])
+			if day.Ordinal != 0 {
+				name = strconv.Itoa(day.Ordinal) + name
+			}
+			days[i] = name
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after the given time for a series that
+// started at start and has had occurred occurrences so far. ok is false once
+// the series is exhausted.
+func (r *RecurrenceRule) Next(start, after time.Time, occurred int) (next time.Time, ok bool) {
+	if r.Count > 0 && occurred >= r.Count {
+		return time.Time{}, false
+	}
+	first := r.periodContaining(start, after)
+	for period := first; period < first+maxRecurrencePeriods; period++ {
+		for _, candidate := range r.periodOccurrences(start, period) {
+			if candidate.Before(start) || !candidate.After(after) {
+				continue
+			}
+			if r.Until != nil && candidate.After(*r.Until) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodContaining returns the period the given time falls in, or an
+// earlier one, so the search for the next occurrence needn't start over from
+// the beginning of the series.
+func (r *RecurrenceRule) periodContaining(start, at time.Time) int {
+	if !at.After(start) {
+		return 0
+	}
+	var elapsed int
+	switch r.Freq {
+	case RecurrenceDaily:
+		elapsed = int(at.Sub(start).Hours() / 24)
+	case RecurrenceWeekly:
+		elapsed = int(at.Sub(start).Hours()/24) / 7
+	case RecurrenceMonthly:
+		elapsed = (at.Year()-start.Year())*12 + int(at.Month()) - int(start.Month())
+	case RecurrenceYearly:
+		elapsed = at.Year() - start.Year()
+	}
+	// Daylight saving and partial periods can put the estimate one late
+	if period := elapsed/r.Interval - 1; period > 0 {
+		return period
+	}
+	return 0
+}
+
+// periodOccurrences lists the occurrences in the nth period (day, week,
+// month or year, times the interval) after the one containing start, in
+// order.
+func (r *RecurrenceRule) periodOccurrences(start time.Time, period int) []time.Time {
+	hour, min, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, start.Nanosecond(), start.Location())
+	}
+	steps := period * r.Interval
+
+	var occurrences []time.Time
+	switch r.Freq {
+	case RecurrenceDaily:
+		occurrences = append(occurrences, start.AddDate(0, 0, steps))
+
+	case RecurrenceWeekly:
+		offset := (int(start.Weekday()) + 6) % 7 // Days since Monday
+		monday := at(start.Year(), start.Month(), start.Day()-offset+7*steps)
+		if len(r.ByDay) == 0 {
+			return []time.Time{monday.AddDate(0, 0, offset)}
+		}
+		for _, day := range r.ByDay {
+			occurrences = append(occurrences, monday.AddDate(0, 0, (int(day.Day)+6)%7))
+		}
+
+	case RecurrenceMonthly:
+		first := at(start.Year(), start.Month()+time.Month(steps), 1)
+		year, month := first.Year(), first.Month()
+		days := daysIn(year, month)
+		switch {
+		case len(r.ByDay) > 0:
+			for _, weekday := range r.ByDay {
+				for _, day := range monthWeekdays(year, month, weekday) {
+					occurrences = append(occurrences, at(year, month, day))
+				}
+			}
+		case len(r.ByMonthDay) > 0:
+			for _, day := range r.ByMonthDay {
+				if day < 0 {
+					day = days + day + 1
+				}
+				if day >= 1 && day <= days {
+					occurrences = append(occurrences, at(year, month, day))
+				}
+			}
+		case start.Day() <= days:
+			// Months without the start's day are skipped, as RFC 5545 does
+			occurrences = append(occurrences, at(year, month, start.Day()))
+		}
+
+	case RecurrenceYearly:
+		year := start.Year() + steps
+		if start.Day() <= daysIn(year, start.Month()) {
+			occurrences = append(occurrences, at(year, start.Month(), start.Day()))
+		}
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return occurrences
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// monthWeekdays returns the days of the month matching a BYDAY entry.
+func monthWeekdays(year int, month time.Month, weekday RecurrenceWeekday) []int {
+	var days []int
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	for day := 1 + (int(weekday.Day)-int(first)+7)%7; day <= daysIn(year, month); day += 7 {
+		days = append(days, day)
+	}
+	switch {
+	case weekday.Ordinal > 0 && weekday.Ordinal <= len(days):
+		return []int{days[weekday.Ordinal-1]}
+	case weekday.Ordinal < 0 && -weekday.Ordinal <= len(days):
+		return []int{days[len(days)+weekday.Ordinal]}
+	case weekday.Ordinal != 0:
+		return nil
+	}
+	return days
+}
+
+// TaskRecurrence is a series of recurring tasks. Only the current
+// occurrence exists as a task ahead of time; the next one is created when
+// it is completed or its due date arrives.
+type TaskRecurrence struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Rule          string     `gorm:"type:varchar(255);not null" json:"rule"`
+	StartDate     time.Time  `gorm:"not null" json:"start_date"`            // Due date of the first occurrence
+	Occurrences   int        `gorm:"not null;default:1" json:"occurrences"` // Created or skipped so far, including the first
+	CurrentTaskID uint       `gorm:"not null;index" json:"current_task_id"`
+	NextDueDate   *time.Time `json:"next_due_date"` // nil once the series has ended
+	EndedAt       *time.Time `json:"ended_at"`      // Set when stopped or exhausted
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ParsedRule parses the stored rule.
+func (r *TaskRecurrence) ParsedRule() (*RecurrenceRule, error) {
+	return ParseRecurrenceRule(r.Rule)
+}
+
+type TaskRecurrenceRepository interface {
+	Create(ctx context.Context, recurrence *TaskRecurrence) error
+	GetByID(ctx context.Context, id uint) (*TaskRecurrence, error)
+	// GetForUpdate loads the series and locks it until the transaction ends.
+	GetForUpdate(ctx context.Context, id uint) (*TaskRecurrence, error)
+	Update(ctx context.Context, recurrence *TaskRecurrence) error
+	// GetDueIDs returns up to limit running series whose current task is due
+	// by now.
+	GetDueIDs(ctx context.Context, now time.Time, limit int) ([]uint, error)
+}
+
+type TaskRecurrenceUsecase interface {
+	// SetRecurrence makes the task the current occurrence of a new series,
+	// replacing any series it belonged to. endAfter, when positive, ends the
+	// series after that many occurrences.
+	SetRecurrence(ctx context.Context, taskID uint, rule string, endAfter int) (*TaskRecurrence, error)
+	GetRecurrence(ctx context.Context, taskID uint) (*TaskRecurrence, error)
+	// Skip moves the task to the following occurrence without creating one
+	// for the skipped date.
+	Skip(ctx context.Context, taskID uint) (*TaskRecurrence, error)
+	// Stop ends the series; existing tasks are kept.
+	Stop(ctx context.Context, taskID uint) (*TaskRecurrence, error)
+	// GenerateDue creates the next occurrence of every series whose current
+	// task is due, skipping any that are already past, and returns how many
+	// it created.
+	GenerateDue(ctx context.Context) (int, error)
+}
@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string // Canonical form; empty when the rule is invalid
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;interval=2;byday=mo,fr", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"},
+		{"FREQ=MONTHLY;BYDAY=2TU,-1FR", "FREQ=MONTHLY;BYDAY=2TU,-1FR"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=6", "FREQ=MONTHLY;COUNT=6;BYMONTHDAY=1,-1"},
+		{"FREQ=DAILY;UNTIL=20250110T120000Z", "FREQ=DAILY;UNTIL=20250110T120000Z"},
+		{"FREQ=DAILY;UNTIL=20250110", "FREQ=DAILY;UNTIL=20250110T235959Z"},
+		{"FREQ=WEEKLY;WKST=MO;INTERVAL=1", "FREQ=WEEKLY"},
+		{"", ""},
+		{"INTERVAL=2", ""},
+		{"FREQ=HOURLY", ""},
+		{"FREQ=DAILY;FREQ=WEEKLY", ""},
+		{"FREQ=DAILY;INTERVAL=0", ""},
+		{"FREQ=DAILY;COUNT=3;UNTIL=20250110", ""},
+		{"FREQ=DAILY;BYDAY=MO", ""},
+		{"FREQ=WEEKLY;BYDAY=1MO", ""},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", ""},
+		{"FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=0", ""},
+		{"FREQ=MONTHLY;BYDAY=6MO", ""},
+		{"FREQ=MONTHLY;BYDAY=XX", ""},
+		{"FREQ=WEEKLY;WKST=SU", ""},
+		{"FREQ=DAILY;BYHOUR=9", ""},
+		{"FREQ=DAILY;INTERVAL", ""},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRecurrenceRule(tt.value)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ParseRecurrenceRule(%q) error = %v, want ErrInvalidInput", tt.value, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRecurrenceRule(%q) error = %v", tt.value, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("ParseRecurrenceRule(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		after time.Time
+		count int // Occurrences so far; 1 after the first
+		want  []time.Time
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY",
+			start: date(2025, 1, 30),
+			want:  []time.Time{date(2025, 1, 31), date(2025, 2, 1), date(2025, 2, 2)},
+		},
+		{
+			name:  "every third day",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 4), date(2025, 1, 7)},
+		},
+		{
+			name:  "far behind",
+			rule:  "FREQ=DAILY",
+			start: date(2023, 1, 1),
+			after: date(2025, 3, 1),
+			want:  []time.Time{date(2025, 3, 2), date(2025, 3, 3)},
+		},
+		{
+			name:  "weekly keeps the weekday",
+			rule:  "FREQ=WEEKLY",
+			start: date(2025, 1, 1), // Wednesday
+			want:  []time.Time{date(2025, 1, 8), date(2025, 1, 15)},
+		},
+		{
+			name:  "weekly by day",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 3), date(2025, 1, 6), date(2025, 1, 10)},
+		},
+		{
+			name:  "fortnightly starting on a sunday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU",
+			start: date(2025, 1, 5), // Sunday, the end of its week
+			want:  []time.Time{date(2025, 1, 13), date(2025, 1, 19), date(2025, 1, 27)},
+		},
+		{
+			name:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY",
+			start: date(2025, 1, 31),
+			want:  []time.Time{date(2025, 3, 31), date(2025, 5, 31), date(2025, 7, 31)},
+		},
+		{
+			name:  "bymonthday 31",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: date(2025, 3, 31),
+			want:  []time.Time{date(2025, 5, 31), date(2025, 7, 31), date(2025, 8, 31)},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2024, 1, 31),
+			want:  []time.Time{date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30)},
+		},
+		{
+			name:  "first and fifteenth",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=15,1",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 15), date(2025, 2, 1), date(2025, 2, 15)},
+		},
+		{
+			name:  "second tuesday",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: date(2025, 1, 14),
+			want:  []time.Time{date(2025, 2, 11), date(2025, 3, 11)},
+		},
+		{
+			name:  "last friday",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: date(2025, 1, 31),
+			want:  []time.Time{date(2025, 2, 28), date(2025, 3, 28)},
+		},
+		{
+			name:  "fifth monday only in some months",
+			rule:  "FREQ=MONTHLY;BYDAY=5MO",
+			start: date(2025, 3, 31),
+			want:  []time.Time{date(2025, 6, 30), date(2025, 9, 29)},
+		},
+		{
+			name:  "yearly on leap day",
+			rule:  "FREQ=YEARLY",
+			start: date(2024, 2, 29),
+			want:  []time.Time{date(2028, 2, 29)},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 2), date(2025, 1, 3)},
+		},
+		{
+			name:  "until is inclusive",
+			rule:  "FREQ=DAILY;UNTIL=20250103T093000Z",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 2), date(2025, 1, 3)},
+		},
+		{
+			name:  "date-only until covers the day",
+			rule:  "FREQ=WEEKLY;UNTIL=20250115",
+			start: date(2025, 1, 1),
+			want:  []time.Time{date(2025, 1, 8), date(2025, 1, 15)},
+		},
+		{
+			name:  "until before the next occurrence",
+			rule:  "FREQ=MONTHLY;UNTIL=20250227",
+			start: date(2025, 1, 28),
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, occurred := tt.after, 1
+			if after.IsZero() {
+				after = tt.start
+			}
+			for _, want := range tt.want {
+				next, ok := rule.Next(tt.start, after, occurred)
+				if !ok {
+					t.Fatalf("Next(%v) ended, want %v", after, want)
+				}
+				if !next.Equal(want) {
+					t.Fatalf("Next(%v) = %v, want %v", after, next, want)
+				}
+				after = next
+				occurred++
+			}
+			if rule.Count > 0 || rule.Until != nil {
+				if next, ok := rule.Next(tt.start, after, occurred); ok {
+					t.Errorf("Next(%v) = %v, want the series to end", after, next)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleNextNeverMatching(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC)
+	if next, ok := rule.Next(start, start, 1); ok {
+		t.Errorf("Next = %v, want no occurrence", next)
+	}
+}
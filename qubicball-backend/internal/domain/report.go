@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TaskStatusSnapshot is the number of a project's tasks in one status at
+// the end of a day. Snapshots are recorded daily by the scheduler.
+type TaskStatusSnapshot struct {
+	ProjectID uint       `gorm:"primaryKey" json:"project_id"`
+	Date      time.Time  `gorm:"primaryKey;type:date" json:"date"`
+	Status    TaskStatus `gorm:"primaryKey;type:varchar(20)" json:"status"`
+	Count     int64      `gorm:"not null" json:"count"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type BurndownPoint struct {
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Total     int64   `json:"total"`
+	Completed int64   `json:"completed"`
+	Remaining int64   `json:"remaining"`
+	Ideal     float64 `json:"ideal"` // Straight line from the first remaining count to zero at To
+}
+
+type Burndown struct {
+	ProjectID uint            `json:"project_id"`
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Points    []BurndownPoint `json:"points"`
+}
+
+type CumulativeFlowPoint struct {
+	Date   string               `json:"date"`
+	Counts map[TaskStatus]int64 `json:"counts"`
+}
+
+type CumulativeFlow struct {
+	ProjectID uint                  `json:"project_id"`
+	From      string                `json:"from"`
+	To        string                `json:"to"`
+	Statuses  []TaskStatus          `json:"statuses"`
+	Points    []CumulativeFlowPoint `json:"points"`
+}
+
+type SnapshotRepository interface {
+	// RecordDaily replaces the snapshots for date with the current counts of
+	// every project.
+	RecordDaily(ctx context.Context, date time.Time) error
+	GetRange(ctx context.Context, projectID uint, from, to time.Time) ([]TaskStatusSnapshot, error)
+	// GetCurrent returns the project's live counts in snapshot form.
+	GetCurrent(ctx context.Context, projectID uint) ([]TaskStatusSnapshot, error)
+}
+
+type ReportUsecase interface {
+	RecordDailySnapshots(ctx context.Context) error
+	GetBurndown(ctx context.Context, projectID uint, from, to time.Time) (*Burndown, error)
+	GetCumulativeFlow(ctx context.Context, projectID uint, from, to time.Time) (*CumulativeFlow, error)
+}
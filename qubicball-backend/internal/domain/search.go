@@ -0,0 +1,54 @@
+package domain
+
+import "context"
+
+type SearchResultType string
+
+const (
+	SearchResultProject SearchResultType = "project"
+	SearchResultTask    SearchResultType = "task"
+)
+
+// SearchResult is one ranked match. Highlight and Snippet are HTML-escaped
+// with matched terms wrapped in <mark> tags.
+type SearchResult struct {
+	Type      SearchResultType `json:"type"`
+	ID        uint             `json:"id"`
+	ProjectID uint             `json:"project_id"`
+	Title     string           `json:"title"`
+	Status    TaskStatus       `json:"status,omitempty"`
+	Highlight string           `json:"highlight"` // Title or name with matches marked
+	Snippet   string           `json:"snippet"`   // Best fragments of the description
+	Rank      float64          `json:"rank"`
+}
+
+// SearchMatchStart and SearchMatchStop delimit matched terms in raw
+// headlines. They are private-use characters so the text can be escaped
+// before the delimiters become markup.
+const (
+	SearchMatchStart = "\uE000"
+	SearchMatchStop  = "\uE001"
+)
+
+type SearchResults struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
+
+// SearchOptions bounds a search. A non-nil AssigneeID restricts task matches
+// to that user's tasks.
+type SearchOptions struct {
+	Types      []SearchResultType
+	AssigneeID *uint
+	Limit      int
+}
+
+type SearchRepository interface {
+	SearchProjects(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	SearchTasks(ctx context.Context, query string, assigneeID *uint, limit int) ([]SearchResult, error)
+}
+
+type SearchUsecase interface {
+	// Search matches projects and tasks visible to the request's actor.
+	Search(ctx context.Context, query string, opts SearchOptions) (*SearchResults, error)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SprintStatus string
+
+const (
+	SprintStatusPlanned   SprintStatus = "planned"
+	SprintStatusActive    SprintStatus = "active"
+	SprintStatusCompleted SprintStatus = "completed"
+)
+
+func (s SprintStatus) Valid() bool {
+	switch s {
+	case SprintStatusPlanned, SprintStatusActive, SprintStatusCompleted:
+		return true
+	}
+	return false
+}
+
+type Sprint struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	ProjectID   uint            `gorm:"not null;index" json:"project_id"`
+	Project     Project         `gorm:"foreignKey:ProjectID" json:"-"`
+	Name        string          `gorm:"not null" json:"name"`
+	Goal        string          `json:"goal"`
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     time.Time       `json:"end_date"`
+	Status      SprintStatus    `gorm:"type:varchar(20);default:'planned'" json:"status"`
+	StartedAt   *time.Time      `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	Progress    *SprintProgress `gorm:"-" json:"progress,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+type SprintProgress struct {
+	Total             int64                `json:"total"`
+	Completed         int64                `json:"completed"`
+	CompletionPercent float64              `json:"completion_percent"`
+	ByStatus          map[TaskStatus]int64 `json:"by_status"`
+}
+
+// SprintCompletion reports where a completed sprint's unfinished tasks went.
+type SprintCompletion struct {
+	Sprint         *Sprint `json:"sprint"`
+	CarriedOver    int64   `json:"carried_over"`
+	CarriedOverTo  *uint   `json:"carried_over_to"` // nil means the project backlog
+	CompletedTasks int64   `json:"completed_tasks"`
+}
+
+type SprintRepository interface {
+	Create(ctx context.Context, sprint *Sprint) error
+	GetByID(ctx context.Context, id uint) (*Sprint, error)
+	GetByProjectID(ctx context.Context, projectID uint) ([]Sprint, error)
+	GetActiveByProjectID(ctx context.Context, projectID uint) (*Sprint, error)
+	// LockActive serializes sprint starts in a project until the
+	// transaction ends.
+	LockActive(ctx context.Context, projectID uint) error
+	// UpdateDetails saves the name, goal and dates; UpdateStatus saves the
+	// status and when the sprint started and completed.
+	UpdateDetails(ctx context.Context, sprint *Sprint) error
+	UpdateStatus(ctx context.Context, sprint *Sprint) error
+	Delete(ctx context.Context, id uint) error
+	GetProgress(ctx context.Context, sprintIDs []uint) (map[uint]*SprintProgress, error)
+}
+
+type SprintUsecase interface {
+	Create(ctx context.Context, sprint *Sprint) error
+	GetByID(ctx context.Context, projectID, id uint) (*Sprint, error)
+	GetByProjectID(ctx context.Context, projectID uint) ([]Sprint, error)
+	Update(ctx context.Context, sprint *Sprint) error
+	Delete(ctx context.Context, projectID, id uint) error
+	Start(ctx context.Context, projectID, id uint) (*Sprint, error)
+	Complete(ctx context.Context, projectID, id uint, carryOverTo *uint) (*SprintCompletion, error)
+	AssignTasks(ctx context.Context, projectID, id uint, taskIDs []uint) (int64, error)
+	RemoveTask(ctx context.Context, projectID, id, taskID uint) error
+}
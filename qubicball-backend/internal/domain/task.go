@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,41 +17,392 @@ const (
 	TaskStatusOverdue    TaskStatus = "Overdue"
 )
 
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityMedium TaskPriority = "medium"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+func (p TaskPriority) Valid() bool {
+	switch p {
+	case TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent:
+		return true
+	}
+	return false
+}
+
 type Task struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"not null" json:"title"`
-	Description string         `json:"description"`
-	Status      TaskStatus     `gorm:"type:varchar(20);default:'Not Started'" json:"status"`
-	DueDate     time.Time      `json:"due_date"`
-	ProjectID   uint           `gorm:"not null" json:"project_id"`
-	Project     Project        `gorm:"foreignKey:ProjectID" json:"-"`
-	AssigneeID  *uint          `json:"assignee_id"`
-	Assignee    *User          `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
-	Version     int            `gorm:"default:1" json:"version"` // Optimistic Locking
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Title       string       `gorm:"not null" json:"title"`
+	Description string       `json:"description"`
+	Status      TaskStatus   `gorm:"type:varchar(20);default:'Not Started'" json:"status"`
+	Priority    TaskPriority `gorm:"type:varchar(10);not null;default:'medium'" json:"priority"`
+	// Rank orders the project's tasks manually. Ranks compare byte-wise, so
+	// a task can be moved between two others by giving it a rank between
+	// theirs. Written via TaskUsecase.Move.
+	Rank         string     `gorm:"type:varchar(64) COLLATE \"C\";not null;default:'';index:idx_tasks_project_rank,priority:2" json:"rank"`
+	DueDate      time.Time  `json:"due_date"`
+	StartDate    *time.Time `json:"start_date"`
+	DurationDays *int       `json:"duration_days"` // Planned length for timeline views
+	// ClearStartDate and ClearDurationDays unset the schedule on update,
+	// where leaving out start_date or duration_days keeps it.
+	ClearStartDate    bool `gorm:"-" json:"clear_start_date,omitempty"`
+	ClearDurationDays bool `gorm:"-" json:"clear_duration_days,omitempty"`
+	// CustomFieldValues sets custom field values on create, keyed by field
+	// ID. Later changes go through CustomFieldUsecase.
+	CustomFieldValues map[uint]json.RawMessage `gorm:"-" json:"custom_field_values,omitempty"`
+	// EstimateMinutes is the original estimate and RemainingMinutes what is
+	// left of it; logging time counts remaining down. LoggedMinutes totals
+	// the task's time entries and is only written by them.
+	EstimateMinutes  *int    `json:"estimate_minutes"`
+	RemainingMinutes *int    `json:"remaining_minutes"`
+	LoggedMinutes    int     `gorm:"not null;default:0" json:"logged_minutes"`
+	ProjectID        uint    `gorm:"not null;index:idx_tasks_project_rank,priority:1" json:"project_id"`
+	Project          Project `gorm:"foreignKey:ProjectID" json:"-"`
+	// AssigneeID is the primary assignee, kept for clients predating
+	// Assignees, which always includes it.
+	AssigneeID   *uint              `json:"assignee_id"`
+	Assignee     *User              `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
+	AssigneeIDs  []uint             `gorm:"-" json:"assignee_ids,omitempty"` // Replaces Assignees on create and update
+	Assignees    []User             `gorm:"many2many:task_assignees" json:"assignees,omitempty"`
+	Watchers     []User             `gorm:"many2many:task_watchers" json:"watchers,omitempty"` // Only loaded for single tasks
+	LabelIDs     []uint             `gorm:"-" json:"label_ids,omitempty"`                      // Replaces Labels on create and update
+	Labels       []Label            `gorm:"many2many:task_labels" json:"labels,omitempty"`
+	ParentID     *uint              `gorm:"index" json:"parent_id"` // Set for subtasks; 0 on update detaches
+	SprintID     *uint              `gorm:"index" json:"sprint_id"`
+	RecurrenceID *uint              `gorm:"index" json:"recurrence_id"` // Series the task is an occurrence of
+	Sprint       *Sprint            `gorm:"foreignKey:SprintID" json:"-"`
+	CustomFields []CustomFieldValue `gorm:"foreignKey:TaskID" json:"custom_fields,omitempty"` // Written via CustomFieldUsecase
+	Subtasks     *SubtaskProgress   `gorm:"-" json:"subtasks,omitempty"`                      // Roll-up over all descendants
+	BlockedBy    []TaskRef          `gorm:"-" json:"blocked_by,omitempty"`                    // Open and finished blockers
+	Blocks       []TaskRef          `gorm:"-" json:"blocks,omitempty"`
+	Version      int                `gorm:"default:1" json:"version"` // Optimistic Locking
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt     `gorm:"index" json:"-"`
+}
+
+// MaxBulkTasks bounds how many tasks one bulk operation may name.
+const MaxBulkTasks = 100
+
+// MaxTaskDepth is how many levels a task hierarchy may have, counting the
+// top-level task.
+const MaxTaskDepth = 5
+
+// SubtaskProgress rolls up completion across all of a task's descendants.
+type SubtaskProgress struct {
+	Total             int64   `json:"total"`
+	Completed         int64   `json:"completed"`
+	CompletionPercent float64 `json:"completion_percent"`
+}
+
+// TaskNode is a task within a subtree. Depth is relative to the subtree's
+// root, which has depth 0.
+type TaskNode struct {
+	Task
+	Depth    int         `json:"depth"`
+	Children []*TaskNode `json:"children"`
+}
+
+// TaskFilter narrows task listings. Zero fields don't filter; ranges include
+// their From bound and exclude their To bound.
+type TaskFilter struct {
+	Statuses     []TaskStatus
+	Priorities   []TaskPriority
+	AssigneeIDs  []uint
+	Unassigned   bool   // Matches unassigned tasks, alongside any AssigneeIDs
+	LabelIDs     []uint // Matches tasks with any of the labels
+	DueFrom      *time.Time
+	DueTo        *time.Time
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+	Text         string // Case-insensitive match on title or description
+	CustomFields []CustomFieldFilter
+}
+
+type TaskSortField string
+
+const (
+	TaskSortCreated TaskSortField = "created_at"
+	TaskSortDueDate TaskSortField = "due_date"
+	TaskSortStatus  TaskSortField = "status"
+	TaskSortUpdated TaskSortField = "updated_at"
+	// TaskSortPriority puts the most urgent tasks first
+	TaskSortPriority TaskSortField = "priority"
+	TaskSortRank     TaskSortField = "rank"
+)
+
+func (f TaskSortField) Valid() bool {
+	switch f {
+	case TaskSortCreated, TaskSortDueDate, TaskSortStatus, TaskSortUpdated, TaskSortPriority, TaskSortRank:
+		return true
+	}
+	return false
+}
+
+// TaskSort orders a listing. Ties are broken by task ID so pages are stable.
+type TaskSort struct {
+	Field TaskSortField
+	Desc  bool
+}
+
+// TaskQuery is a filtered, sorted and optionally paginated task listing.
+type TaskQuery struct {
+	Filter TaskFilter
+	Sort   TaskSort
+	// Limit caps the page size; zero returns every match.
+	Limit int
+	// Cursor continues from a previous page's NextCursor. It is only valid
+	// with the same sort.
+	Cursor string
+}
+
+// CacheKey encodes the query for use in list cache keys.
+func (q TaskQuery) CacheKey() string {
+	b, _ := json.Marshal(q)
+	return string(b)
+}
+
+// TaskMove places a task directly after AfterID and/or before BeforeID,
+// tasks of the same project. A board move also sets Status, and the
+// neighbours then have to be in that column. Version, required with Status,
+// is the task version the client last saw.
+type TaskMove struct {
+	BeforeID *uint      `json:"before_id"`
+	AfterID  *uint      `json:"after_id"`
+	Status   TaskStatus `json:"status"`
+	Version  *int       `json:"version"`
+}
+
+// TaskBulkAction is an operation applied to many tasks at once.
+type TaskBulkAction string
+
+const (
+	TaskBulkStatus   TaskBulkAction = "status"
+	TaskBulkReassign TaskBulkAction = "reassign"
+	TaskBulkDueDate  TaskBulkAction = "due_date"
+	TaskBulkMove     TaskBulkAction = "move_project"
+	TaskBulkDelete   TaskBulkAction = "delete"
+)
+
+func (a TaskBulkAction) Valid() bool {
+	switch a {
+	case TaskBulkStatus, TaskBulkReassign, TaskBulkDueDate, TaskBulkMove, TaskBulkDelete:
+		return true
+	}
+	return false
+}
+
+// TaskBulkItem is a task to operate on and the version the client last saw.
+type TaskBulkItem struct {
+	ID      uint `json:"id"`
+	Version int  `json:"version"`
+}
+
+// TaskBulkOperation applies Action to Tasks. Each action reads only its own
+// parameter: Status, AssigneeIDs (replacing every assignee; empty
+// unassigns), DueDate or ProjectID.
+type TaskBulkOperation struct {
+	Action      TaskBulkAction `json:"action"`
+	Tasks       []TaskBulkItem `json:"tasks"`
+	Status      TaskStatus     `json:"status"`
+	AssigneeIDs []uint         `json:"assignee_ids"`
+	DueDate     *time.Time     `json:"due_date"`
+	ProjectID   uint           `json:"project_id"`
+}
+
+type TaskBulkOutcome string
+
+const (
+	TaskBulkApplied    TaskBulkOutcome = "applied"
+	TaskBulkRolledBack TaskBulkOutcome = "rolled_back" // Would have applied, but another task failed
+	TaskBulkConflict   TaskBulkOutcome = "conflict"
+	TaskBulkNotFound   TaskBulkOutcome = "not_found"
+	TaskBulkInvalid    TaskBulkOutcome = "invalid"
+	TaskBulkForbidden  TaskBulkOutcome = "forbidden"
+)
+
+// TaskBulkResult is the outcome for one task. Version is the task's new
+// version once applied.
+type TaskBulkResult struct {
+	ID      uint            `json:"id"`
+	Outcome TaskBulkOutcome `json:"outcome"`
+	Version int             `json:"version,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// TaskBulkReport lists results in request order. Bulk operations are all or
+// nothing: Applied is false when any task failed, and then none changed.
+type TaskBulkReport struct {
+	Action  TaskBulkAction   `json:"action"`
+	Applied bool             `json:"applied"`
+	Results []TaskBulkResult `json:"results"`
+}
+
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// TaskAssignee is the join row behind Task.Assignees.
+type TaskAssignee struct {
+	TaskID    uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+// TaskWatcher is the join row behind Task.Watchers. Watchers are notified of
+// new comments on the task, like its assignees. Watching a task does not
+// make it visible to a member; only assignment does.
+type TaskWatcher struct {
+	TaskID    uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+// LabelSet returns the IDs of the task's labels: LabelIDs when set,
+// otherwise the loaded Labels.
+func (t *Task) LabelSet() []uint {
+	if t.LabelIDs != nil {
+		return t.LabelIDs
+	}
+	ids := make([]uint, len(t.Labels))
+	for i, label := range t.Labels {
+		ids[i] = label.ID
+	}
+	return ids
+}
+
+// AssigneeSet returns every user assigned to the task: AssigneeIDs when set,
+// otherwise the loaded Assignees, plus the primary assignee.
+func (t *Task) AssigneeSet() []uint {
+	ids := t.AssigneeIDs
+	if ids == nil {
+		for _, user := range t.Assignees {
+			ids = append(ids, user.ID)
+		}
+	}
+	set := make([]uint, 0, len(ids)+1)
+	seen := make(map[uint]bool, len(ids)+1)
+	if t.AssigneeID != nil {
+		set = append(set, *t.AssigneeID)
+		seen[*t.AssigneeID] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			set = append(set, id)
+			seen[id] = true
+		}
+	}
+	return set
 }
 
 type TaskRepository interface {
 	Create(ctx context.Context, task *Task) error
+	CreateBatch(ctx context.Context, tasks []Task) error
 	GetByID(ctx context.Context, id uint) (*Task, error)
-	GetByProjectID(ctx context.Context, projectID uint) ([]Task, error)
+	GetByProjectID(ctx context.Context, projectID uint, query TaskQuery) (*TaskPage, error)
 	Update(ctx context.Context, task *Task) error
+	// AddLoggedMinutes adds minutes, which may be negative, to the task's
+	// logged time and takes them off its remaining estimate, never below
+	// zero. The task's version is left alone.
+	AddLoggedMinutes(ctx context.Context, id uint, minutes int) error
 	Delete(ctx context.Context, id uint) error
 	GetOverdueTasks(ctx context.Context) ([]Task, error)
-	MarkAsOverdue(ctx context.Context) error
-	GetByAssigneeID(ctx context.Context, assigneeID uint) ([]Task, error)
-	GetByProjectIDAndAssigneeID(ctx context.Context, projectID uint, assigneeID uint) ([]Task, error)
+	// MarkAsOverdue moves unfinished tasks past their due date to Overdue and
+	// returns the changes made.
+	MarkAsOverdue(ctx context.Context) ([]TaskStatusChange, error)
+	// GetByAssigneeID and GetByProjectIDAndAssigneeID match tasks the user
+	// is any of the assignees of.
+	GetByAssigneeID(ctx context.Context, assigneeID uint, query TaskQuery) (*TaskPage, error)
+	GetByProjectIDAndAssigneeID(ctx context.Context, projectID uint, assigneeID uint, query TaskQuery) (*TaskPage, error)
+	// GetProjectStats limits DueSoon to tasks assigned to dueSoonAssigneeID
+	// when it is set; the counts always cover the whole project.
+	GetProjectStats(ctx context.Context, projectID uint, now, dueSoonUntil time.Time, dueSoonAssigneeID *uint) (*ProjectStats, error)
+	GetStatusCounts(ctx context.Context, projectID uint) (map[TaskStatus]int64, error)
+	SetSprint(ctx context.Context, projectID uint, taskIDs []uint, sprintID *uint) (int64, error)
+	MoveUnfinishedFromSprint(ctx context.Context, fromSprintID uint, toSprintID *uint) (int64, error)
+	ClearSprint(ctx context.Context, sprintID uint) error
+	// GetAncestorIDs returns the task's ancestors, nearest first.
+	GetAncestorIDs(ctx context.Context, id uint) ([]uint, error)
+	// GetSubtree returns the root and its descendants up to maxDepth levels
+	// below it, unnested and ordered by depth.
+	GetSubtree(ctx context.Context, rootID uint, maxDepth int) ([]TaskNode, error)
+	GetSubtaskProgress(ctx context.Context, parentIDs []uint) (map[uint]SubtaskProgress, error)
+	SetParent(ctx context.Context, id uint, parentID *uint) error
+	ReparentChildren(ctx context.Context, parentID uint, newParentID *uint) error
+	// GetDeletedIDs returns up to limit tasks soft-deleted before the cutoff,
+	// oldest first.
+	GetDeletedIDs(ctx context.Context, before time.Time, limit int) ([]uint, error)
+	// Purge permanently removes soft-deleted tasks along with their comments,
+	// custom field values, notifications and activity. Attachments are left
+	// to the caller, which also owns their blobs.
+	Purge(ctx context.Context, ids []uint) error
+	SetRecurrence(ctx context.Context, id uint, recurrenceID *uint) error
+	// SetAssignees replaces the task's assignees with userIDs.
+	SetAssignees(ctx context.Context, id uint, userIDs []uint) error
+	// SetLabels replaces the task's labels with labelIDs.
+	SetLabels(ctx context.Context, id uint, labelIDs []uint) error
+	AddWatcher(ctx context.Context, id, userID uint) error
+	RemoveWatcher(ctx context.Context, id, userID uint) error
+	// LockRanks serializes rank changes and moves into WIP-limited columns
+	// within the project until the transaction ends.
+	LockRanks(ctx context.Context, projectID uint) error
+	// GetLastRank returns the highest rank in the project, "" when it has no
+	// tasks.
+	GetLastRank(ctx context.Context, projectID uint) (string, error)
+	// GetNeighborRank returns the rank of the task ordered directly before
+	// (or after) the given position, skipping excludeID. ok is false at
+	// either end of the project.
+	GetNeighborRank(ctx context.Context, projectID uint, rank string, id, excludeID uint, after bool) (neighbor string, ok bool, err error)
+	SetRank(ctx context.Context, id uint, rank string) error
+	// MoveToProject writes the task's project, status, rank and parent and
+	// leaves its sprint. Time already logged stays with the old project.
+	// Like Update it checks the task's version.
+	MoveToProject(ctx context.Context, task *Task) error
+	// GetRankOrder returns the project's task IDs in rank order.
+	GetRankOrder(ctx context.Context, projectID uint) ([]uint, error)
+	// SetRanks assigns ranks[i] to ids[i].
+	SetRanks(ctx context.Context, ids []uint, ranks []string) error
+	// GetProjectsToRebalance returns projects with unranked tasks or ranks
+	// longer than maxLength.
+	GetProjectsToRebalance(ctx context.Context, maxLength int) ([]uint, error)
 }
 
 type TaskUsecase interface {
 	Create(ctx context.Context, task *Task) error
 	GetByID(ctx context.Context, id uint) (*Task, error)
-	GetByProjectID(ctx context.Context, projectID uint) ([]Task, error)
+	// GetByProjectID lists a project's tasks. Custom field filters are given
+	// as raw values keyed by field ID and resolved against the project.
+	GetByProjectID(ctx context.Context, projectID uint, query TaskQuery, customFields map[uint]string) (*TaskPage, error)
 	Update(ctx context.Context, task *Task) error
 	Delete(ctx context.Context, id uint) error
 	MarkOverdueTasks(ctx context.Context) error
-	GetByAssigneeID(ctx context.Context, assigneeID uint) ([]Task, error)
-	GetByProjectIDAndAssigneeID(ctx context.Context, projectID uint, assigneeID uint) ([]Task, error)
+	GetByAssigneeID(ctx context.Context, assigneeID uint, query TaskQuery) (*TaskPage, error)
+	GetByProjectIDAndAssigneeID(ctx context.Context, projectID uint, assigneeID uint, query TaskQuery, customFields map[uint]string) (*TaskPage, error)
+	// GetSubtree returns the task with its descendants nested as children.
+	GetSubtree(ctx context.Context, id uint) (*TaskNode, error)
+	// PurgeDeletedTasks permanently removes tasks soft-deleted before the
+	// cutoff, including their attachments, and returns how many it removed.
+	PurgeDeletedTasks(ctx context.Context, before time.Time) (int, error)
+	// Move repositions the task and, for board moves, changes its status in
+	// the same transaction. Only the moved task's rank changes, unless the
+	// project's ranks have to be rebalanced to make room.
+	Move(ctx context.Context, id uint, move TaskMove) (*Task, error)
+	// Bulk applies one operation to up to MaxBulkTasks tasks in a single
+	// transaction. Failures of individual tasks are reported rather than
+	// returned as errors.
+	Bulk(ctx context.Context, op TaskBulkOperation) (*TaskBulkReport, error)
+	// RebalanceRanks respaces the ranks of projects whose ranks have grown
+	// long, returning how many projects it rebalanced.
+	RebalanceRanks(ctx context.Context) (int, error)
+	// Watch adds userID to the task's watchers and returns them. Members may
+	// only add or remove themselves.
+	Watch(ctx context.Context, id, userID uint) ([]User, error)
+	Unwatch(ctx context.Context, id, userID uint) ([]User, error)
 }
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type TaskActivityAction string
+
+const (
+	TaskActivityCreated TaskActivityAction = "created"
+	TaskActivityUpdated TaskActivityAction = "updated"
+	TaskActivityDeleted TaskActivityAction = "deleted"
+)
+
+// TaskActivity is one entry in a task's history. Updates get an entry per
+// changed field with the values before and after; values are formatted as
+// text, and nil means the field was empty.
+type TaskActivity struct {
+	ID        uint               `gorm:"primaryKey" json:"id"`
+	TaskID    uint               `gorm:"not null;index" json:"task_id"`
+	ProjectID uint               `gorm:"not null;index" json:"project_id"`
+	ActorID   *uint              `json:"actor_id"` // nil for system jobs
+	Actor     *User              `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	Action    TaskActivityAction `gorm:"type:varchar(20);not null" json:"action"`
+	Field     string             `gorm:"type:varchar(50)" json:"field,omitempty"`
+	OldValue  *string            `json:"old_value"`
+	NewValue  *string            `json:"new_value"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// TaskStatusChange is a status change made in bulk, e.g. by the overdue job.
+type TaskStatusChange struct {
+	TaskID    uint
+	ProjectID uint
+	From      TaskStatus
+	To        TaskStatus
+}
+
+// TaskActivityQuery pages through activity newest first. BeforeID continues
+// from the last entry of the previous page.
+type TaskActivityQuery struct {
+	Limit    int
+	BeforeID uint
+	// AssigneeID limits a project feed to tasks assigned to the user
+	AssigneeID *uint
+}
+
+type TaskActivityPage struct {
+	Activities []TaskActivity
+	NextCursor string // BeforeID for the next page, empty on the last one
+}
+
+type TaskActivityRepository interface {
+	CreateBatch(ctx context.Context, activities []TaskActivity) error
+	GetByTaskID(ctx context.Context, taskID uint, query TaskActivityQuery) ([]TaskActivity, error)
+	GetByProjectID(ctx context.Context, projectID uint, query TaskActivityQuery) ([]TaskActivity, error)
+}
+
+type TaskActivityUsecase interface {
+	GetByTaskID(ctx context.Context, taskID uint, query TaskActivityQuery) (*TaskActivityPage, error)
+	// GetByProjectID returns the project's feed. Members only see activity
+	// on tasks assigned to them.
+	GetByProjectID(ctx context.Context, projectID uint, query TaskActivityQuery) (*TaskActivityPage, error)
+}
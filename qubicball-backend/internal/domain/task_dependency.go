@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TaskDependency records that BlockerID blocks BlockedID. The tasks may be
+// in different projects.
+type TaskDependency struct {
+	BlockerID   uint      `gorm:"primaryKey" json:"blocker_id"`
+	Blocker     Task      `gorm:"foreignKey:BlockerID" json:"-"`
+	BlockedID   uint      `gorm:"primaryKey;index" json:"blocked_id"`
+	Blocked     Task      `gorm:"foreignKey:BlockedID" json:"-"`
+	CreatedByID *uint     `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TaskRef is a short reference to a related task.
+type TaskRef struct {
+	ID        uint       `json:"id"`
+	Title     string     `json:"title"`
+	Status    TaskStatus `json:"status"`
+	ProjectID uint       `json:"project_id"`
+}
+
+// TaskDependencies lists the tasks on either side of a task's dependencies.
+type TaskDependencies struct {
+	BlockedBy []TaskRef `json:"blocked_by"`
+	Blocks    []TaskRef `json:"blocks"`
+}
+
+// TaskDependencyEdge is a dependency with the related task's details.
+type TaskDependencyEdge struct {
+	BlockerID uint
+	BlockedID uint
+	Task      TaskRef // The side of the edge the lookup didn't start from
+}
+
+type TaskDependencyRepository interface {
+	Create(ctx context.Context, dependency *TaskDependency) error
+	Delete(ctx context.Context, blockerID, blockedID uint) error
+	DeleteByTask(ctx context.Context, taskID uint) error
+	// LockGraph serialises dependency changes until the transaction ends.
+	LockGraph(ctx context.Context) error
+	// Reaches reports whether from blocks to, directly or transitively.
+	Reaches(ctx context.Context, from, to uint) (bool, error)
+	// GetBlockers returns the tasks blocking each of taskIDs.
+	GetBlockers(ctx context.Context, taskIDs []uint) ([]TaskDependencyEdge, error)
+	// GetBlocked returns the tasks each of taskIDs blocks.
+	GetBlocked(ctx context.Context, taskIDs []uint) ([]TaskDependencyEdge, error)
+}
+
+type TaskDependencyUsecase interface {
+	// AddBlocker records that blockerID blocks taskID.
+	AddBlocker(ctx context.Context, taskID, blockerID uint) (*TaskDependencies, error)
+	RemoveBlocker(ctx context.Context, taskID, blockerID uint) error
+	GetDependencies(ctx context.Context, taskID uint) (*TaskDependencies, error)
+}
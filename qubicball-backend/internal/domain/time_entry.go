@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TimeEntry is time a user spent on a task, logged by hand or by stopping a
+// timer. ProjectID is copied from the task so reports don't depend on the
+// task still existing.
+type TimeEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TaskID    uint      `gorm:"not null;index" json:"task_id"`
+	ProjectID uint      `gorm:"not null;index:idx_time_entries_project_started,priority:1" json:"project_id"`
+	UserID    uint      `gorm:"not null;index:idx_time_entries_user_started,priority:1" json:"user_id"`
+	User      *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	StartedAt time.Time `gorm:"not null;index:idx_time_entries_project_started,priority:2;index:idx_time_entries_user_started,priority:2" json:"started_at"`
+	Minutes   int       `gorm:"not null" json:"minutes"`
+	Note      string    `gorm:"type:varchar(500)" json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RunningTimer is a user's running timer. The live timers are kept in Redis;
+// this table holds a periodic snapshot of them to recover from. A stopped
+// timer keeps its row with StoppedAt set, so a snapshot taken just before
+// the stop can't bring it back.
+type RunningTimer struct {
+	UserID    uint       `gorm:"primaryKey" json:"user_id"`
+	TaskID    uint       `gorm:"not null" json:"task_id"`
+	ProjectID uint       `gorm:"not null" json:"project_id"`
+	StartedAt time.Time  `gorm:"not null" json:"started_at"`
+	Note      string     `gorm:"type:varchar(500)" json:"note"`
+	StoppedAt *time.Time `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
+
+// TimeEntryChanges edits a time entry. Zero Minutes and StartedAt and a nil
+// Note keep the current value; an empty Note clears it.
+type TimeEntryChanges struct {
+	Minutes   int
+	StartedAt time.Time
+	Note      *string
+}
+
+type TimeReportGroup string
+
+const (
+	TimeReportByUser    TimeReportGroup = "user"
+	TimeReportByProject TimeReportGroup = "project"
+	TimeReportByTask    TimeReportGroup = "task"
+	TimeReportByDay     TimeReportGroup = "day"
+)
+
+func (g TimeReportGroup) Valid() bool {
+	switch g {
+	case TimeReportByUser, TimeReportByProject, TimeReportByTask, TimeReportByDay:
+		return true
+	}
+	return false
+}
+
+// TimeReportQuery selects the entries started within From and To, both
+// whole days, optionally narrowed to a project or user.
+type TimeReportQuery struct {
+	From      time.Time
+	To        time.Time
+	ProjectID *uint
+	UserID    *uint
+	GroupBy   TimeReportGroup
+}
+
+// TimeReportRow totals one group. ID is the user, project or task ID, and
+// Date the day for reports grouped by day.
+type TimeReportRow struct {
+	ID      uint    `json:"id,omitempty"`
+	Date    string  `json:"date,omitempty"`
+	Label   string  `json:"label"`
+	Minutes int64   `json:"minutes"`
+	Hours   float64 `json:"hours"`
+}
+
+type TimeReport struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	GroupBy      TimeReportGroup `json:"group_by"`
+	TotalMinutes int64           `json:"total_minutes"`
+	TotalHours   float64         `json:"total_hours"`
+	Rows         []TimeReportRow `json:"rows"`
+}
+
+type TimeEntryRepository interface {
+	Create(ctx context.Context, entry *TimeEntry) error
+	GetByID(ctx context.Context, id uint) (*TimeEntry, error)
+	GetByTaskID(ctx context.Context, taskID uint) ([]TimeEntry, error)
+	Update(ctx context.Context, entry *TimeEntry) error
+	Delete(ctx context.Context, id uint) error
+	// GetReport totals the matching entries by the query's group, ordered
+	// by label or date.
+	GetReport(ctx context.Context, query TimeReportQuery) ([]TimeReportRow, error)
+
+	// GetTimer returns the user's snapshotted timer unless it was stopped.
+	GetTimer(ctx context.Context, userID uint) (*RunningTimer, error)
+	// StopTimer marks the snapshot of timer as stopped, recording it if it
+	// was never snapshotted. A newer timer of the user is left alone.
+	StopTimer(ctx context.Context, timer *RunningTimer) error
+	// SaveTimers upserts running timers into the snapshot. Timers missing
+	// from the list are kept, and stopped ones are not restarted.
+	SaveTimers(ctx context.Context, timers []RunningTimer) error
+}
+
+type TimeTrackingUsecase interface {
+	// LogTime records a manual entry for the request's actor.
+	LogTime(ctx context.Context, taskID uint, entry *TimeEntry) error
+	GetEntries(ctx context.Context, taskID uint) ([]TimeEntry, error)
+	// UpdateEntry changes an entry's minutes, start or note. Only its author
+	// may edit it.
+	UpdateEntry(ctx context.Context, taskID, id uint, changes TimeEntryChanges) (*TimeEntry, error)
+	// DeleteEntry removes an entry; its author, admins and managers may.
+	DeleteEntry(ctx context.Context, taskID, id uint) error
+
+	// StartTimer starts the actor's timer on a task. A user has at most one
+	// running timer.
+	StartTimer(ctx context.Context, taskID uint, note string) (*RunningTimer, error)
+	// StopTimer stops the actor's timer and logs the time as an entry.
+	StopTimer(ctx context.Context) (*TimeEntry, error)
+	GetTimer(ctx context.Context) (*RunningTimer, error)
+	// PersistTimers snapshots the running timers to the database and
+	// returns how many are running.
+	PersistTimers(ctx context.Context) (int, error)
+
+	// GetReport totals logged time. Members only see their own time.
+	GetReport(ctx context.Context, query TimeReportQuery) (*TimeReport, error)
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type TimelineGrouping string
+
+const (
+	TimelineByAssignee TimelineGrouping = "assignee"
+	TimelineBySprint   TimelineGrouping = "sprint"
+)
+
+// TimelineItem is a task placed on the timeline. Start and End are derived
+// from the task's start date, duration and due date.
+type TimelineItem struct {
+	TaskID     uint       `json:"task_id"`
+	Title      string     `json:"title"`
+	Status     TaskStatus `json:"status"`
+	AssigneeID *uint      `json:"assignee_id"`
+	SprintID   *uint      `json:"sprint_id"`
+	Start      time.Time  `json:"start"`
+	End        time.Time  `json:"end"`
+	DueDate    time.Time  `json:"due_date"`
+	Milestone  bool       `json:"milestone"` // No span, only a due date
+}
+
+type TimelineGroup struct {
+	ID    *uint          `json:"id"` // nil for the unassigned / backlog group
+	Label string         `json:"label"`
+	Items []TimelineItem `json:"items"`
+}
+
+// TimelineLink connects two tasks on the timeline, e.g. a blocker and the
+// task it blocks.
+type TimelineLink struct {
+	FromTaskID uint   `json:"from_task_id"`
+	ToTaskID   uint   `json:"to_task_id"`
+	Type       string `json:"type"`
+}
+
+type ProjectTimeline struct {
+	ProjectID   uint             `json:"project_id"`
+	GroupBy     TimelineGrouping `json:"group_by"`
+	Start       *time.Time       `json:"start"` // Earliest item start, nil when empty
+	End         *time.Time       `json:"end"`
+	Groups      []TimelineGroup  `json:"groups"`
+	Links       []TimelineLink   `json:"links"`
+	Unscheduled []TimelineItem   `json:"unscheduled"` // Tasks with neither start nor due date
+}
+
+type TimelineUsecase interface {
+	// GetProjectTimeline builds the project's timeline. A non-nil assigneeID
+	// limits it to that user's tasks.
+	GetProjectTimeline(ctx context.Context, projectID uint, groupBy TimelineGrouping, assigneeID *uint) (*ProjectTimeline, error)
+}
@@ -21,6 +21,7 @@ type User struct {
 	Password  string         `gorm:"not null" json:"-"`
 	Name      string         `gorm:"not null" json:"name"`
 	Role      Role           `gorm:"type:varchar(20);default:'member'" json:"role"`
+	Active    bool           `gorm:"not null;default:true" json:"active"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -31,6 +32,11 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id uint) (*User, error)
 	GetAll(ctx context.Context) ([]User, error)
+	GetByEmails(ctx context.Context, emails []string) ([]User, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]User, error)
+	// GetByHandles matches mention handles, case-insensitively, against
+	// full emails or the part before the @.
+	GetByHandles(ctx context.Context, handles []string) ([]User, error)
 }
 
 type UserUsecase interface {
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxStatusLength matches the tasks.status column.
+const maxStatusLength = 20
+
+// WorkflowTransition allows moving a task from one status to another. When
+// Roles is set, only users with one of those roles may make the move.
+type WorkflowTransition struct {
+	From  TaskStatus `json:"from"`
+	To    TaskStatus `json:"to"`
+	Roles []Role     `json:"roles,omitempty"`
+}
+
+// Workflow is a project's set of task statuses and the moves allowed between
+// them. Every workflow keeps Completed, which progress reporting counts as
+// done, and Overdue, which the scheduler sets on late tasks from any status.
+type Workflow struct {
+	Initial     TaskStatus           `json:"initial"`
+	Statuses    []TaskStatus         `json:"statuses"`
+	Transitions []WorkflowTransition `json:"transitions"`
+}
+
+// DefaultWorkflow is used by projects without a workflow of their own.
+// Reopening a completed task is reserved for admins and managers.
+func DefaultWorkflow() *Workflow {
+	managers := []Role{RoleAdmin, RoleManager}
+	return &Workflow{
+		Initial:  TaskStatusNotStarted,
+		Statuses: []TaskStatus{TaskStatusNotStarted, TaskStatusInProgress, TaskStatusOverdue, TaskStatusCompleted},
+		Transitions: []WorkflowTransition{
+			{From: TaskStatusNotStarted, To: TaskStatusInProgress},
+			{From: TaskStatusNotStarted, To: TaskStatusCompleted},
+			{From: TaskStatusInProgress, To: TaskStatusNotStarted},
+			{From: TaskStatusInProgress, To: TaskStatusCompleted},
+			{From: TaskStatusOverdue, To: TaskStatusInProgress},
+			{From: TaskStatusOverdue, To: TaskStatusCompleted},
+			{From: TaskStatusCompleted, To: TaskStatusNotStarted, Roles: managers},
+			{From: TaskStatusCompleted, To: TaskStatusInProgress, Roles: managers},
+		},
+	}
+}
+
+func (w Workflow) Value() (driver.Value, error) {
+	b, err := json.Marshal(w)
+	return string(b), err
+}
+
+func (w *Workflow) Scan(src interface{}) error {
+	return scanJSON(src, w)
+}
+
+func (w *Workflow) Has(status TaskStatus) bool {
+	for _, s := range w.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the workflow is well formed.
+func (w *Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("%w: a workflow needs at least one status", ErrInvalidInput)
+	}
+	seen := make(map[TaskStatus]bool, len(w.Statuses))
+	for _, status := range w.Statuses {
+		if strings.TrimSpace(string(status)) == "" || len(status) > maxStatusLength {
+			return fmt.Errorf("%w: status names must be 1 to %d characters", ErrInvalidInput, maxStatusLength)
+		}
+		if seen[status] {
+			return fmt.Errorf("%w: status %q is listed twice", ErrInvalidInput, status)
+		}
+		seen[status] = true
+	}
+	for _, required := range []TaskStatus{TaskStatusCompleted, TaskStatusOverdue} {
+		if !seen[required] {
+			return fmt.Errorf("%w: every workflow must include the %q status", ErrInvalidInput, required)
+		}
+	}
+	if !seen[w.Initial] {
+		return fmt.Errorf("%w: initial status %q is not in the workflow", ErrInvalidInput, w.Initial)
+	}
+
+	pairs := make(map[[2]TaskStatus]bool, len(w.Transitions))
+	for _, t := range w.Transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return fmt.Errorf("%w: transition %q -> %q uses a status that is not in the workflow", ErrInvalidInput, t.From, t.To)
+		}
+		if t.From == t.To {
+			return fmt.Errorf("%w: transition %q -> %q goes nowhere", ErrInvalidInput, t.From, t.To)
+		}
+		if pairs[[2]TaskStatus{t.From, t.To}] {
+			return fmt.Errorf("%w: transition %q -> %q is listed twice", ErrInvalidInput, t.From, t.To)
+		}
+		pairs[[2]TaskStatus{t.From, t.To}] = true
+		for _, role := range t.Roles {
+			if role != RoleAdmin && role != RoleManager && role != RoleMember {
+				return fmt.Errorf("%w: transition %q -> %q names unknown role %q", ErrInvalidInput, t.From, t.To, role)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckStatus reports whether status belongs to the workflow.
+func (w *Workflow) CheckStatus(status TaskStatus) error {
+	if !w.Has(status) {
+		return fmt.Errorf("%w: unknown status %q, expected one of %s", ErrInvalidInput, status, quoteStatuses(w.Statuses))
+	}
+	return nil
+}
+
+// CheckTransition reports whether a user with role may move a task from one
+// status to another.
+func (w *Workflow) CheckTransition(from, to TaskStatus, role Role) error {
+	if err := w.CheckStatus(to); err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	var next []TaskStatus
+	for _, t := range w.Transitions {
+		if t.From != from {
+			continue
+		}
+		if t.To != to {
+			next = append(next, t.To)
+			continue
+		}
+		if len(t.Roles) == 0 {
+			return nil
+		}
+		for _, allowed := range t.Roles {
+			if allowed == role {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: only %s users can move a task from %q to %q", ErrForbidden, joinRoles(t.Roles), from, to)
+	}
+
+	if len(next) == 0 {
+		return fmt.Errorf("%w: tasks in %q cannot be moved to another status", ErrInvalidInput, from)
+	}
+	return fmt.Errorf("%w: cannot move a task from %q to %q, allowed next statuses are %s", ErrInvalidInput, from, to, quoteStatuses(next))
+}
+
+func quoteStatuses(statuses []TaskStatus) string {
+	quoted := make([]string, len(statuses))
+	for i, status := range statuses {
+		quoted[i] = fmt.Sprintf("%q", status)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func joinRoles(roles []Role) string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return strings.Join(names, " or ")
+}
+
+type WorkflowUsecase interface {
+	// GetWorkflow returns the project's workflow, or the default one.
+	GetWorkflow(ctx context.Context, projectID uint) (*Workflow, error)
+	// UpdateWorkflow replaces the project's workflow; nil restores the
+	// default.
+	UpdateWorkflow(ctx context.Context, projectID uint, workflow *Workflow) (*Workflow, error)
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowValidate(t *testing.T) {
+	valid := func() *Workflow {
+		return &Workflow{
+			Initial:  "Todo",
+			Statuses: []TaskStatus{"Todo", "Review", TaskStatusOverdue, TaskStatusCompleted},
+			Transitions: []WorkflowTransition{
+				{From: "Todo", To: "Review"},
+				{From: "Review", To: TaskStatusCompleted, Roles: []Role{RoleManager}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(w *Workflow)
+		ok     bool
+	}{
+		{"valid", func(w *Workflow) {}, true},
+		{"no statuses", func(w *Workflow) { w.Statuses = nil }, false},
+		{"blank status", func(w *Workflow) { w.Statuses = append(w.Statuses, " ") }, false},
+		{"status too long", func(w *Workflow) { w.Statuses = append(w.Statuses, TaskStatus(strings.Repeat("x", 21))) }, false},
+		{"duplicate status", func(w *Workflow) { w.Statuses = append(w.Statuses, "Todo") }, false},
+		{"missing Completed", func(w *Workflow) {
+			w.Statuses = []TaskStatus{"Todo", "Review", TaskStatusOverdue}
+			w.Transitions = nil
+		}, false},
+		{"missing Overdue", func(w *Workflow) { w.Statuses = []TaskStatus{"Todo", "Review", TaskStatusCompleted} }, false},
+		{"initial not listed", func(w *Workflow) { w.Initial = "Backlog" }, false},
+		{"transition to unknown status", func(w *Workflow) {
+			w.Transitions = append(w.Transitions, WorkflowTransition{From: "Todo", To: "Done"})
+		}, false},
+		{"transition to itself", func(w *Workflow) {
+			w.Transitions = append(w.Transitions, WorkflowTransition{From: "Todo", To: "Todo"})
+		}, false},
+		{"duplicate transition", func(w *Workflow) {
+			w.Transitions = append(w.Transitions, WorkflowTransition{From: "Todo", To: "Review"})
+		}, false},
+		{"unknown role", func(w *Workflow) { w.Transitions[0].Roles = []Role{"owner"} }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := valid()
+			tt.modify(w)
+			err := w.Validate()
+			if tt.ok && err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("Validate() = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+
+	if err := DefaultWorkflow().Validate(); err != nil {
+		t.Errorf("default workflow: %v", err)
+	}
+}
+
+func TestWorkflowCheckTransition(t *testing.T) {
+	w := DefaultWorkflow()
+	tests := []struct {
+		from, to TaskStatus
+		role     Role
+		want     error
+	}{
+		{TaskStatusNotStarted, TaskStatusInProgress, RoleMember, nil},
+		{TaskStatusNotStarted, TaskStatusNotStarted, RoleMember, nil},
+		{TaskStatusInProgress, TaskStatusCompleted, RoleMember, nil},
+		{TaskStatusCompleted, TaskStatusInProgress, RoleManager, nil},
+		{TaskStatusCompleted, TaskStatusInProgress, RoleAdmin, nil},
+		{TaskStatusCompleted, TaskStatusInProgress, RoleMember, ErrForbidden},
+		{TaskStatusNotStarted, TaskStatusOverdue, RoleAdmin, ErrInvalidInput},
+		{TaskStatusNotStarted, "Blocked", RoleAdmin, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		err := w.CheckTransition(tt.from, tt.to, tt.role)
+		if tt.want == nil && err != nil {
+			t.Errorf("CheckTransition(%q, %q, %s) = %v", tt.from, tt.to, tt.role, err)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("CheckTransition(%q, %q, %s) = %v, want %v", tt.from, tt.to, tt.role, err, tt.want)
+		}
+	}
+
+	// A status without outgoing transitions says so
+	dead := &Workflow{
+		Initial:  "Todo",
+		Statuses: []TaskStatus{"Todo", TaskStatusOverdue, TaskStatusCompleted},
+	}
+	err := dead.CheckTransition("Todo", TaskStatusCompleted, RoleAdmin)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "cannot be moved") {
+		t.Errorf("CheckTransition from a dead end = %v", err)
+	}
+}
@@ -25,10 +25,88 @@ func NewDatabase() *gorm.DB {
 		log.Fatal("Failed to connect to database: ", err)
 	}
 
-	err = db.AutoMigrate(&domain.User{}, &domain.Project{}, &domain.Task{})
+	// Custom join tables have to be registered before the tasks table is
+	// migrated
+	if err := db.SetupJoinTable(&domain.Task{}, "Assignees", &domain.TaskAssignee{}); err != nil {
+		log.Fatal("Failed to set up task assignees: ", err)
+	}
+	if err := db.SetupJoinTable(&domain.Task{}, "Watchers", &domain.TaskWatcher{}); err != nil {
+		log.Fatal("Failed to set up task watchers: ", err)
+	}
+	if err := db.SetupJoinTable(&domain.Task{}, "Labels", &domain.TaskLabel{}); err != nil {
+		log.Fatal("Failed to set up task labels: ", err)
+	}
+
+	err = db.AutoMigrate(&domain.User{}, &domain.Project{}, &domain.ProjectFavorite{}, &domain.Sprint{}, &domain.Task{},
+		&domain.CustomField{}, &domain.CustomFieldValue{}, &domain.AuditLog{},
+		&domain.TaskStatusSnapshot{}, &domain.TaskDependency{}, &domain.Comment{}, &domain.CommentMention{},
+		&domain.CommentRevision{}, &domain.Notification{}, &domain.Attachment{},
+		&domain.TaskActivity{}, &domain.TaskRecurrence{}, &domain.TimeEntry{}, &domain.RunningTimer{},
+		&domain.Label{})
 	if err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
+	if err := migrateSearchColumns(db); err != nil {
+		log.Fatal("Failed to migrate search columns: ", err)
+	}
+	if err := migrateTaskAssignees(db); err != nil {
+		log.Fatal("Failed to migrate task assignees: ", err)
+	}
+	if err := migrateLabelNames(db); err != nil {
+		log.Fatal("Failed to migrate label names: ", err)
+	}
+	if err := migrateActiveSprints(db); err != nil {
+		log.Fatal("Failed to migrate active sprints: ", err)
+	}
 
 	return db
 }
+
+// migrateTaskAssignees backfills task_assignees from the primary assignee of
+// tasks assigned before multiple assignees existed.
+func migrateTaskAssignees(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO task_assignees (task_id, user_id, created_at)
+		SELECT id, assignee_id, created_at FROM tasks
+		WHERE assignee_id IS NOT NULL
+		ON CONFLICT DO NOTHING`).Error
+}
+
+// migrateLabelNames makes label names unique per project regardless of
+// case, which AutoMigrate can't express as it needs an expression index.
+func migrateLabelNames(db *gorm.DB) error {
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_project_name
+		ON labels (project_id, lower(name))`).Error
+}
+
+// migrateActiveSprints allows at most one active sprint per project, which
+// AutoMigrate can't express as it needs a partial index.
+func migrateActiveSprints(db *gorm.DB) error {
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_sprints_project_active
+		ON sprints (project_id) WHERE status = 'active' AND deleted_at IS NULL`).Error
+}
+
+// migrateSearchColumns adds the generated tsvector columns and GIN indexes
+// behind full-text search. AutoMigrate can't express generated columns, and
+// the structs don't map them since they are only read in SQL.
+func migrateSearchColumns(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+				setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_projects_search_vector ON projects USING GIN (search_vector)`,
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+				setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
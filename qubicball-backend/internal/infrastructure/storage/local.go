@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"qubicball-backend/internal/domain"
+)
+
+// localBlobStore keeps blobs as files under a root directory, one file per
+// key.
+type localBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) (domain.BlobStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &localBlobStore{root: root}, nil
+}
+
+func (s *localBlobStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+func (s *localBlobStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// Written beside the target and renamed so readers never see a partial
+	// file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("blob %s: wrote %d bytes, expected %d", key, written, size)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (s *localBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *localBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return domain.ErrBlobNotFound
+	}
+	return err
+}
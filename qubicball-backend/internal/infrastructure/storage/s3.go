@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket in the path rather than the host name,
+	// as most S3-compatible servers expect.
+	PathStyle bool
+}
+
+// s3BlobStore keeps blobs as objects in an S3-compatible bucket. Requests
+// are signed with AWS Signature Version 4.
+type s3BlobStore struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3BlobStore(config S3Config) (domain.BlobStore, error) {
+	if config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 storage needs a bucket and credentials")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	return &s3BlobStore{config: config, client: &http.Client{}}, nil
+}
+
+func (s *s3BlobStore) objectURL(key string) *url.URL {
+	u, _ := url.Parse(strings.TrimRight(s.config.Endpoint, "/"))
+	segments := strings.Split(key, "/")
+	if s.config.PathStyle {
+		segments = append([]string{s.config.Bucket}, segments...)
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	u.RawPath = u.Path + "/" + strings.Join(segments, "/")
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	return u
+}
+
+func (s *s3BlobStore) do(ctx context.Context, method, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		// The body is streamed, so it can't be hashed up front
+		req.ContentLength = size
+		payloadHash = "UNSIGNED-PAYLOAD"
+	}
+	s.sign(req, payloadHash, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, domain.ErrBlobNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	resp, err := s.do(ctx, http.MethodPut, key, content, size, header)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3BlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, nil)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, resp.Body.Close()
+}
+
+func (s *s3BlobStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// sign adds the Signature Version 4 headers to req.
+func (s *s3BlobStore) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode percent-encodes everything but the unreserved characters, as
+// Signature Version 4 requires.
+func uriEncode(s string) string {
+	var out strings.Builder
+	for _, b := range []byte(s) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || strings.IndexByte("-_.~", b) >= 0 {
+			out.WriteByte(b)
+		} else {
+			fmt.Fprintf(&out, "%%%02X", b)
+		}
+	}
+	return out.String()
+}
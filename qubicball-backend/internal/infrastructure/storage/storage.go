@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+)
+
+// NewBlobStore builds the blob store selected by ATTACHMENT_STORAGE: "local"
+// (the default) or "s3".
+func NewBlobStore() domain.BlobStore {
+	var store domain.BlobStore
+	var err error
+	switch backend := os.Getenv("ATTACHMENT_STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("ATTACHMENT_DIR")
+		if dir == "" {
+			dir = "data/attachments"
+		}
+		store, err = NewLocalBlobStore(dir)
+	case "s3":
+		pathStyle, _ := strconv.ParseBool(os.Getenv("S3_FORCE_PATH_STYLE"))
+		store, err = NewS3BlobStore(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       pathStyle,
+		})
+	default:
+		log.Fatalf("Unknown ATTACHMENT_STORAGE %q", backend)
+	}
+	if err != nil {
+		log.Fatal("Failed to set up attachment storage: ", err)
+	}
+	return store
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) domain.AttachmentRepository {
+	return &attachmentRepository{db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return conn(ctx, r.db).Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uint) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := conn(ctx, r.db).Preload("Uploader").First(&attachment, id).Error
+	return &attachment, err
+}
+
+func (r *attachmentRepository) GetByTaskID(ctx context.Context, taskID uint) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	err := conn(ctx, r.db).Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Preload("Uploader").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) GetByTaskAndChecksum(ctx context.Context, taskID uint, checksum string) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := conn(ctx, r.db).Where("task_id = ? AND checksum = ?", taskID, checksum).
+		Preload("Uploader").First(&attachment).Error
+	return &attachment, err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uint) error {
+	result := conn(ctx, r.db).Delete(&domain.Attachment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *attachmentRepository) DeleteByTaskIDs(ctx context.Context, taskIDs []uint) ([]string, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+	var attachments []domain.Attachment
+	err := conn(ctx, r.db).Clauses(clause.Returning{Columns: []clause.Column{{Name: "storage_key"}}}).
+		Where("task_id IN ?", taskIDs).Delete(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, attachment := range attachments {
+		if !seen[attachment.StorageKey] {
+			seen[attachment.StorageKey] = true
+			keys = append(keys, attachment.StorageKey)
+		}
+	}
+	return keys, nil
+}
+
+func (r *attachmentRepository) CountByStorageKey(ctx context.Context, key string) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&domain.Attachment{}).Where("storage_key = ?", key).Count(&count).Error
+	return count, err
+}
+
+func (r *attachmentRepository) LockBlob(ctx context.Context, key string) error {
+	return conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "attachment:"+key).Error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) domain.AuditLogRepository {
+	return &auditLogRepository{db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	return conn(ctx, r.db).Create(entry).Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) domain.CommentRepository {
+	return &commentRepository{db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return conn(ctx, r.db).Create(comment).Error
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uint) (*domain.Comment, error) {
+	var comment domain.Comment
+	err := conn(ctx, r.db).Preload("Author").Preload("Mentions").First(&comment, id).Error
+	return &comment, err
+}
+
+func (r *commentRepository) GetByTaskID(ctx context.Context, taskID uint) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	err := conn(ctx, r.db).Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Preload("Author").Preload("Mentions").Find(&comments).Error
+	return comments, err
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	db := conn(ctx, r.db)
+	result := db.Model(&domain.Comment{}).
+		Where("id = ?", comment.ID).
+		Updates(map[string]interface{}{
+			"body":      comment.Body,
+			"edited_at": comment.EditedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := db.Where("comment_id = ?", comment.ID).Delete(&domain.CommentMention{}).Error; err != nil {
+		return err
+	}
+	if len(comment.Mentions) == 0 {
+		return nil
+	}
+	for i := range comment.Mentions {
+		comment.Mentions[i].CommentID = comment.ID
+	}
+	return db.Create(&comment.Mentions).Error
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.Comment{}, id).Error
+}
+
+func (r *commentRepository) CreateRevision(ctx context.Context, revision *domain.CommentRevision) error {
+	return conn(ctx, r.db).Create(revision).Error
+}
+
+func (r *commentRepository) GetRevisions(ctx context.Context, commentID uint) ([]domain.CommentRevision, error) {
+	var revisions []domain.CommentRevision
+	err := conn(ctx, r.db).Where("comment_id = ?", commentID).Order("created_at DESC, id DESC").Find(&revisions).Error
+	return revisions, err
+}
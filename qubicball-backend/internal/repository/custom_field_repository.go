@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type customFieldRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomFieldRepository(db *gorm.DB) domain.CustomFieldRepository {
+	return &customFieldRepository{db}
+}
+
+func (r *customFieldRepository) Create(ctx context.Context, field *domain.CustomField) error {
+	return conn(ctx, r.db).Create(field).Error
+}
+
+func (r *customFieldRepository) GetByID(ctx context.Context, id uint) (*domain.CustomField, error) {
+	var field domain.CustomField
+	err := conn(ctx, r.db).First(&field, id).Error
+	return &field, err
+}
+
+func (r *customFieldRepository) GetByProjectID(ctx context.Context, projectID uint) ([]domain.CustomField, error) {
+	var fields []domain.CustomField
+	err := conn(ctx, r.db).Where("project_id = ?", projectID).Order("position ASC, id ASC").Find(&fields).Error
+	return fields, err
+}
+
+func (r *customFieldRepository) Update(ctx context.Context, field *domain.CustomField) error {
+	result := conn(ctx, r.db).Model(&domain.CustomField{}).
+		Where("id = ?", field.ID).
+		Updates(map[string]interface{}{
+			"name":       field.Name,
+			"options":    field.Options,
+			"required":   field.Required,
+			"position":   field.Position,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *customFieldRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.CustomField{}, id).Error
+}
+
+func (r *customFieldRepository) GetValues(ctx context.Context, taskID uint) ([]domain.CustomFieldValue, error) {
+	var values []domain.CustomFieldValue
+	err := conn(ctx, r.db).Where("task_id = ?", taskID).Order("field_id ASC").Find(&values).Error
+	return values, err
+}
+
+func (r *customFieldRepository) UpsertValues(ctx context.Context, values []domain.CustomFieldValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "field_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&values).Error
+}
+
+func (r *customFieldRepository) DeleteValues(ctx context.Context, taskID uint, fieldIDs []uint) error {
+	if len(fieldIDs) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Where("task_id = ? AND field_id IN ?", taskID, fieldIDs).Delete(&domain.CustomFieldValue{}).Error
+}
+
+func (r *customFieldRepository) DeleteValuesByField(ctx context.Context, fieldID uint) error {
+	return conn(ctx, r.db).Where("field_id = ?", fieldID).Delete(&domain.CustomFieldValue{}).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type labelRepository struct {
+	db *gorm.DB
+}
+
+func NewLabelRepository(db *gorm.DB) domain.LabelRepository {
+	return &labelRepository{db}
+}
+
+func (r *labelRepository) Create(ctx context.Context, label *domain.Label) error {
+	return conn(ctx, r.db).Create(label).Error
+}
+
+func (r *labelRepository) GetByID(ctx context.Context, id uint) (*domain.Label, error) {
+	var label domain.Label
+	err := conn(ctx, r.db).First(&label, id).Error
+	return &label, err
+}
+
+func (r *labelRepository) GetByIDs(ctx context.Context, ids []uint) ([]domain.Label, error) {
+	var labels []domain.Label
+	if len(ids) == 0 {
+		return labels, nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&labels).Error
+	return labels, err
+}
+
+func (r *labelRepository) GetByProjectID(ctx context.Context, projectID uint) ([]domain.Label, error) {
+	// Deleted tasks don't count
+	var labels []domain.Label
+	err := conn(ctx, r.db).
+		Select(`labels.*, (SELECT COUNT(*) FROM task_labels tl JOIN tasks t ON t.id = tl.task_id
+			WHERE tl.label_id = labels.id AND t.deleted_at IS NULL) AS task_count`).
+		Where("labels.project_id = ?", projectID).
+		Order("lower(labels.name), labels.id").
+		Find(&labels).Error
+	return labels, err
+}
+
+func (r *labelRepository) GetByName(ctx context.Context, projectID uint, name string) (*domain.Label, error) {
+	var label domain.Label
+	err := conn(ctx, r.db).Where("project_id = ? AND lower(name) = lower(?)", projectID, name).First(&label).Error
+	return &label, err
+}
+
+func (r *labelRepository) Update(ctx context.Context, label *domain.Label) error {
+	result := conn(ctx, r.db).Model(&domain.Label{}).
+		Where("id = ?", label.ID).
+		Updates(map[string]interface{}{
+			"name":  label.Name,
+			"color": label.Color,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *labelRepository) Delete(ctx context.Context, id uint) error {
+	db := conn(ctx, r.db)
+	if err := db.Where("label_id = ?", id).Delete(&domain.TaskLabel{}).Error; err != nil {
+		return err
+	}
+	result := db.Delete(&domain.Label{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *labelRepository) Merge(ctx context.Context, sourceID, targetID uint) error {
+	// Tasks that already have both labels keep their target row
+	err := conn(ctx, r.db).Exec(`INSERT INTO task_labels (task_id, label_id, created_at)
+		SELECT task_id, ?, created_at FROM task_labels WHERE label_id = ?
+		ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+	if err != nil {
+		return err
+	}
+	return r.Delete(ctx, sourceID)
+}
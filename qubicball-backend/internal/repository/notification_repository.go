@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) domain.NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (r *notificationRepository) CreateBatch(ctx context.Context, notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Create(&notifications).Error
+}
+
+func (r *notificationRepository) GetByUserID(ctx context.Context, userID uint, unreadOnly bool, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	query := conn(ctx, r.db).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id uint) error {
+	result := conn(ctx, r.db).Model(&domain.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	result := conn(ctx, r.db).Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -18,24 +18,34 @@ func NewProjectRepository(db *gorm.DB) domain.ProjectRepository {
 }
 
 func (r *projectRepository) Create(ctx context.Context, project *domain.Project) error {
-	return r.db.WithContext(ctx).Create(project).Error
+	return conn(ctx, r.db).Create(project).Error
 }
 
 func (r *projectRepository) GetByID(ctx context.Context, id uint) (*domain.Project, error) {
 	var project domain.Project
-	err := r.db.WithContext(ctx).Preload("Owner").First(&project, id).Error
+	err := conn(ctx, r.db).Preload("Owner").First(&project, id).Error
 	return &project, err
 }
 
-func (r *projectRepository) GetAll(ctx context.Context, limit, offset int) ([]domain.Project, error) {
+func (r *projectRepository) GetAll(ctx context.Context, filter domain.ProjectFilter, limit, offset int) ([]domain.Project, error) {
 	var projects []domain.Project
-	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Preload("Owner").Find(&projects).Error
+	query := conn(ctx, r.db).Model(&domain.Project{}).
+		Select("projects.*, pf.user_id IS NOT NULL AS is_favorite, COALESCE(pf.pinned, false) AS is_pinned").
+		Joins("LEFT JOIN project_favorites pf ON pf.project_id = projects.id AND pf.user_id = ?", filter.UserID).
+		Where("projects.is_template = ?", filter.Templates)
+	if filter.FavoritesOnly {
+		query = query.Where("pf.user_id IS NOT NULL")
+	}
+
+	err := query.
+		Order("COALESCE(pf.pinned, false) DESC, pf.user_id IS NULL, pf.position, projects.id").
+		Limit(limit).Offset(offset).Preload("Owner").Find(&projects).Error
 	return projects, err
 }
 
 func (r *projectRepository) Update(ctx context.Context, project *domain.Project) error {
 	// Optimistic Locking: Check version
-	result := r.db.WithContext(ctx).Model(&domain.Project{}).
+	result := conn(ctx, r.db).Model(&domain.Project{}).
 		Where("id = ? AND version = ?", project.ID, project.Version).
 		Updates(map[string]interface{}{
 			"name":        project.Name,
@@ -53,6 +63,119 @@ func (r *projectRepository) Update(ctx context.Context, project *domain.Project)
 	return nil
 }
 
+func (r *projectRepository) UpdateOwner(ctx context.Context, id uint, version int, ownerID uint) error {
+	result := conn(ctx, r.db).Model(&domain.Project{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{
+			"owner_id":   ownerID,
+			"version":    version + 1,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *projectRepository) UpdateSettings(ctx context.Context, id uint, settings domain.ProjectSettings) error {
+	result := conn(ctx, r.db).Model(&domain.Project{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"setting_block_parent_completion": settings.BlockParentCompletion,
+			"setting_allow_open_blockers":     settings.AllowOpenBlockers,
+			"updated_at":                      time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *projectRepository) UpdateWIPLimits(ctx context.Context, id uint, limits domain.WIPLimits) error {
+	result := conn(ctx, r.db).Model(&domain.Project{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"wip_limits": limits,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *projectRepository) UpdateWorkflow(ctx context.Context, id uint, workflow *domain.Workflow) error {
+	result := conn(ctx, r.db).Model(&domain.Project{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"workflow":   workflow,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *projectRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&domain.Project{}, id).Error
+	return conn(ctx, r.db).Delete(&domain.Project{}, id).Error
+}
+
+func (r *projectRepository) SaveFavorite(ctx context.Context, favorite *domain.ProjectFavorite) error {
+	return conn(ctx, r.db).Save(favorite).Error
+}
+
+func (r *projectRepository) GetFavorite(ctx context.Context, userID, projectID uint) (*domain.ProjectFavorite, error) {
+	var favorite domain.ProjectFavorite
+	err := conn(ctx, r.db).Where("user_id = ? AND project_id = ?", userID, projectID).First(&favorite).Error
+	return &favorite, err
+}
+
+func (r *projectRepository) GetFavorites(ctx context.Context, userID uint) ([]domain.ProjectFavorite, error) {
+	var favorites []domain.ProjectFavorite
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("position ASC").Find(&favorites).Error
+	return favorites, err
+}
+
+func (r *projectRepository) DeleteFavorite(ctx context.Context, userID, projectID uint) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND project_id = ?", userID, projectID).Delete(&domain.ProjectFavorite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *projectRepository) SetFavoritePositions(ctx context.Context, userID uint, projectIDs []uint) error {
+	db := conn(ctx, r.db)
+	for position, projectID := range projectIDs {
+		err := db.Model(&domain.ProjectFavorite{}).
+			Where("user_id = ? AND project_id = ?", userID, projectID).
+			Updates(map[string]interface{}{
+				"position":   position,
+				"updated_at": time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
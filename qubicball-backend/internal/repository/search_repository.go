@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var (
+	titleHeadlineOptions   = fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", domain.SearchMatchStart, domain.SearchMatchStop)
+	snippetHeadlineOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=20, MinWords=5", domain.SearchMatchStart, domain.SearchMatchStop)
+)
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) domain.SearchRepository {
+	return &searchRepository{db}
+}
+
+func (r *searchRepository) SearchProjects(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	var results []domain.SearchResult
+	err := conn(ctx, r.db).Raw(`
+		SELECT 'project' AS type, p.id, p.id AS project_id, p.name AS title,
+			ts_rank(p.search_vector, q) AS rank,
+			ts_headline('english', p.name, q, ?) AS highlight,
+			ts_headline('english', COALESCE(p.description, ''), q, ?) AS snippet
+		FROM projects p, websearch_to_tsquery('english', ?) q
+		WHERE p.deleted_at IS NULL AND p.search_vector @@ q
+		ORDER BY rank DESC, p.id
+		LIMIT ?`,
+		titleHeadlineOptions, snippetHeadlineOptions, query, limit).
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *searchRepository) SearchTasks(ctx context.Context, query string, assigneeID *uint, limit int) ([]domain.SearchResult, error) {
+	visibility := ""
+	args := []interface{}{titleHeadlineOptions, snippetHeadlineOptions, query}
+	if assigneeID != nil {
+		visibility = "AND EXISTS (SELECT 1 FROM task_assignees ta WHERE ta.task_id = t.id AND ta.user_id = ?)"
+		args = append(args, *assigneeID)
+	}
+	args = append(args, limit)
+
+	var results []domain.SearchResult
+	err := conn(ctx, r.db).Raw(`
+		SELECT 'task' AS type, t.id, t.project_id, t.title, t.status,
+			ts_rank(t.search_vector, q) AS rank,
+			ts_headline('english', t.title, q, ?) AS highlight,
+			ts_headline('english', COALESCE(t.description, ''), q, ?) AS snippet
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id AND p.deleted_at IS NULL,
+			websearch_to_tsquery('english', ?) q
+		WHERE t.deleted_at IS NULL AND t.search_vector @@ q `+visibility+`
+		ORDER BY rank DESC, t.id
+		LIMIT ?`, args...).
+		Scan(&results).Error
+	return results, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type snapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewSnapshotRepository(db *gorm.DB) domain.SnapshotRepository {
+	return &snapshotRepository{db}
+}
+
+func (r *snapshotRepository) RecordDaily(ctx context.Context, date time.Time) error {
+	day := date.Format("2006-01-02")
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Re-runs on the same day replace the earlier rows, including statuses
+		// that have since dropped to zero
+		if err := tx.Where("date = ?", day).Delete(&domain.TaskStatusSnapshot{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO task_status_snapshots (project_id, date, status, count, created_at)
+			SELECT project_id, ?::date, status, COUNT(*), NOW()
+			FROM tasks
+			WHERE deleted_at IS NULL
+			GROUP BY project_id, status`, day).Error
+	})
+}
+
+func (r *snapshotRepository) GetRange(ctx context.Context, projectID uint, from, to time.Time) ([]domain.TaskStatusSnapshot, error) {
+	var snapshots []domain.TaskStatusSnapshot
+	err := conn(ctx, r.db).
+		Where("project_id = ? AND date BETWEEN ? AND ?", projectID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date ASC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (r *snapshotRepository) GetCurrent(ctx context.Context, projectID uint) ([]domain.TaskStatusSnapshot, error) {
+	var snapshots []domain.TaskStatusSnapshot
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Select("project_id, status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).
+		Group("project_id, status").
+		Scan(&snapshots).Error
+	return snapshots, err
+}
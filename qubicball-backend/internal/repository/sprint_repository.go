@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type sprintRepository struct {
+	db *gorm.DB
+}
+
+func NewSprintRepository(db *gorm.DB) domain.SprintRepository {
+	return &sprintRepository{db}
+}
+
+func (r *sprintRepository) Create(ctx context.Context, sprint *domain.Sprint) error {
+	return conn(ctx, r.db).Create(sprint).Error
+}
+
+func (r *sprintRepository) GetByID(ctx context.Context, id uint) (*domain.Sprint, error) {
+	var sprint domain.Sprint
+	err := conn(ctx, r.db).First(&sprint, id).Error
+	return &sprint, err
+}
+
+func (r *sprintRepository) GetByProjectID(ctx context.Context, projectID uint) ([]domain.Sprint, error) {
+	var sprints []domain.Sprint
+	err := conn(ctx, r.db).Where("project_id = ?", projectID).Order("start_date ASC, id ASC").Find(&sprints).Error
+	return sprints, err
+}
+
+func (r *sprintRepository) GetActiveByProjectID(ctx context.Context, projectID uint) (*domain.Sprint, error) {
+	var sprint domain.Sprint
+	err := conn(ctx, r.db).Where("project_id = ? AND status = ?", projectID, domain.SprintStatusActive).First(&sprint).Error
+	return &sprint, err
+}
+
+func (r *sprintRepository) LockActive(ctx context.Context, projectID uint) error {
+	return conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext('sprint_active:' || ?::text))", projectID).Error
+}
+
+func (r *sprintRepository) UpdateDetails(ctx context.Context, sprint *domain.Sprint) error {
+	return r.update(ctx, sprint.ID, map[string]interface{}{
+		"name":       sprint.Name,
+		"goal":       sprint.Goal,
+		"start_date": sprint.StartDate,
+		"end_date":   sprint.EndDate,
+	})
+}
+
+func (r *sprintRepository) UpdateStatus(ctx context.Context, sprint *domain.Sprint) error {
+	return r.update(ctx, sprint.ID, map[string]interface{}{
+		"status":       sprint.Status,
+		"started_at":   sprint.StartedAt,
+		"completed_at": sprint.CompletedAt,
+	})
+}
+
+func (r *sprintRepository) update(ctx context.Context, id uint, columns map[string]interface{}) error {
+	columns["updated_at"] = time.Now()
+	result := conn(ctx, r.db).Model(&domain.Sprint{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *sprintRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.Sprint{}, id).Error
+}
+
+func (r *sprintRepository) GetProgress(ctx context.Context, sprintIDs []uint) (map[uint]*domain.SprintProgress, error) {
+	progress := make(map[uint]*domain.SprintProgress, len(sprintIDs))
+	for _, id := range sprintIDs {
+		progress[id] = &domain.SprintProgress{ByStatus: make(map[domain.TaskStatus]int64)}
+	}
+	if len(sprintIDs) == 0 {
+		return progress, nil
+	}
+
+	var rows []struct {
+		SprintID uint
+		Status   domain.TaskStatus
+		Count    int64
+	}
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Select("sprint_id, status, COUNT(*) AS count").
+		Where("sprint_id IN ?", sprintIDs).
+		Group("sprint_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		p := progress[row.SprintID]
+		p.ByStatus[row.Status] = row.Count
+		p.Total += row.Count
+		if row.Status == domain.TaskStatusCompleted {
+			p.Completed += row.Count
+		}
+	}
+	return progress, nil
+}
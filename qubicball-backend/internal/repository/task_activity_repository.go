@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type taskActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskActivityRepository(db *gorm.DB) domain.TaskActivityRepository {
+	return &taskActivityRepository{db}
+}
+
+func (r *taskActivityRepository) CreateBatch(ctx context.Context, activities []domain.TaskActivity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).CreateInBatches(&activities, 500).Error
+}
+
+func (r *taskActivityRepository) GetByTaskID(ctx context.Context, taskID uint, query domain.TaskActivityQuery) ([]domain.TaskActivity, error) {
+	return r.list(conn(ctx, r.db).Where("task_id = ?", taskID), query)
+}
+
+func (r *taskActivityRepository) GetByProjectID(ctx context.Context, projectID uint, query domain.TaskActivityQuery) ([]domain.TaskActivity, error) {
+	db := conn(ctx, r.db).Where("project_id = ?", projectID)
+	if query.AssigneeID != nil {
+		// Deleted tasks keep their assignees, so their activity stays visible
+		assigned := conn(ctx, r.db).Model(&domain.TaskAssignee{}).Select("task_id").Where("user_id = ?", *query.AssigneeID)
+		db = db.Where("task_id IN (?)", assigned)
+	}
+	return r.list(db, query)
+}
+
+func (r *taskActivityRepository) list(db *gorm.DB, query domain.TaskActivityQuery) ([]domain.TaskActivity, error) {
+	if query.BeforeID != 0 {
+		db = db.Where("id < ?", query.BeforeID)
+	}
+	var activities []domain.TaskActivity
+	err := db.Order("id DESC").Limit(query.Limit).Preload("Actor").Find(&activities).Error
+	return activities, err
+}
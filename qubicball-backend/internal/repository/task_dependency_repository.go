@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type taskDependencyRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskDependencyRepository(db *gorm.DB) domain.TaskDependencyRepository {
+	return &taskDependencyRepository{db}
+}
+
+func (r *taskDependencyRepository) Create(ctx context.Context, dependency *domain.TaskDependency) error {
+	return conn(ctx, r.db).Create(dependency).Error
+}
+
+func (r *taskDependencyRepository) Delete(ctx context.Context, blockerID, blockedID uint) error {
+	result := conn(ctx, r.db).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&domain.TaskDependency{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *taskDependencyRepository) DeleteByTask(ctx context.Context, taskID uint) error {
+	return conn(ctx, r.db).
+		Where("blocker_id = ? OR blocked_id = ?", taskID, taskID).
+		Delete(&domain.TaskDependency{}).Error
+}
+
+// dependencyGraphLock is the advisory lock key guarding cycle checks.
+const dependencyGraphLock = 7_301_239
+
+func (r *taskDependencyRepository) LockGraph(ctx context.Context) error {
+	return conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(?)", dependencyGraphLock).Error
+}
+
+func (r *taskDependencyRepository) Reaches(ctx context.Context, from, to uint) (bool, error) {
+	var found bool
+	// UNION (not UNION ALL) drops revisited rows, so the walk ends even if
+	// the graph already has a cycle
+	err := conn(ctx, r.db).Raw(`
+		WITH RECURSIVE reachable AS (
+			SELECT blocked_id FROM task_dependencies WHERE blocker_id = ?
+			UNION
+			SELECT d.blocked_id FROM task_dependencies d
+			JOIN reachable r ON d.blocker_id = r.blocked_id
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE blocked_id = ?)`, from, to).
+		Scan(&found).Error
+	return found, err
+}
+
+func (r *taskDependencyRepository) GetBlockers(ctx context.Context, taskIDs []uint) ([]domain.TaskDependencyEdge, error) {
+	return r.edges(ctx, "d.blocked_id IN ?", "d.blocker_id", taskIDs)
+}
+
+func (r *taskDependencyRepository) GetBlocked(ctx context.Context, taskIDs []uint) ([]domain.TaskDependencyEdge, error) {
+	return r.edges(ctx, "d.blocker_id IN ?", "d.blocked_id", taskIDs)
+}
+
+// edges loads dependencies matching where, joined to the task in column
+// other.
+func (r *taskDependencyRepository) edges(ctx context.Context, where, other string, taskIDs []uint) ([]domain.TaskDependencyEdge, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		BlockerID uint
+		BlockedID uint
+		ID        uint
+		Title     string
+		Status    domain.TaskStatus
+		ProjectID uint
+	}
+	err := conn(ctx, r.db).Table("task_dependencies d").
+		Select("d.blocker_id, d.blocked_id, t.id, t.title, t.status, t.project_id").
+		Joins("JOIN tasks t ON t.id = "+other+" AND t.deleted_at IS NULL").
+		Where(where, taskIDs).
+		Order("t.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]domain.TaskDependencyEdge, len(rows))
+	for i, row := range rows {
+		edges[i] = domain.TaskDependencyEdge{
+			BlockerID: row.BlockerID,
+			BlockedID: row.BlockedID,
+			Task:      domain.TaskRef{ID: row.ID, Title: row.Title, Status: row.Status, ProjectID: row.ProjectID},
+		}
+	}
+	return edges, nil
+}
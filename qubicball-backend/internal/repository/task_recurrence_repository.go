@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type taskRecurrenceRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskRecurrenceRepository(db *gorm.DB) domain.TaskRecurrenceRepository {
+	return &taskRecurrenceRepository{db}
+}
+
+func (r *taskRecurrenceRepository) Create(ctx context.Context, recurrence *domain.TaskRecurrence) error {
+	return conn(ctx, r.db).Create(recurrence).Error
+}
+
+func (r *taskRecurrenceRepository) GetByID(ctx context.Context, id uint) (*domain.TaskRecurrence, error) {
+	var recurrence domain.TaskRecurrence
+	err := conn(ctx, r.db).First(&recurrence, id).Error
+	return &recurrence, err
+}
+
+func (r *taskRecurrenceRepository) GetForUpdate(ctx context.Context, id uint) (*domain.TaskRecurrence, error) {
+	var recurrence domain.TaskRecurrence
+	err := conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&recurrence, id).Error
+	return &recurrence, err
+}
+
+func (r *taskRecurrenceRepository) Update(ctx context.Context, recurrence *domain.TaskRecurrence) error {
+	return conn(ctx, r.db).Model(recurrence).Select("rule", "occurrences", "current_task_id", "next_due_date", "ended_at", "updated_at").
+		Updates(recurrence).Error
+}
+
+func (r *taskRecurrenceRepository) GetDueIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := conn(ctx, r.db).Model(&domain.TaskRecurrence{}).
+		Joins("JOIN tasks ON tasks.id = task_recurrences.current_task_id AND tasks.deleted_at IS NULL").
+		Where("task_recurrences.ended_at IS NULL AND tasks.due_date <= ?", now).
+		Order("tasks.due_date").Limit(limit).
+		Pluck("task_recurrences.id", &ids).Error
+	return ids, err
+}
@@ -2,11 +2,16 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"qubicball-backend/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type taskRepository struct {
@@ -18,33 +23,295 @@ func NewTaskRepository(db *gorm.DB) domain.TaskRepository {
 }
 
 func (r *taskRepository) Create(ctx context.Context, task *domain.Task) error {
-	return r.db.WithContext(ctx).Create(task).Error
+	if err := conn(ctx, r.db).Omit("Assignees", "Watchers", "Labels").Create(task).Error; err != nil {
+		return err
+	}
+	return r.createJoinRows(ctx, []domain.Task{*task})
+}
+
+func (r *taskRepository) CreateBatch(ctx context.Context, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	if err := conn(ctx, r.db).Omit("Assignees", "Watchers", "Labels").CreateInBatches(tasks, 100).Error; err != nil {
+		return err
+	}
+	return r.createJoinRows(ctx, tasks)
+}
+
+// createJoinRows writes the assignee and label rows for newly created tasks.
+func (r *taskRepository) createJoinRows(ctx context.Context, tasks []domain.Task) error {
+	var assignees []domain.TaskAssignee
+	var labels []domain.TaskLabel
+	for i := range tasks {
+		for _, userID := range tasks[i].AssigneeSet() {
+			assignees = append(assignees, domain.TaskAssignee{TaskID: tasks[i].ID, UserID: userID})
+		}
+		for _, labelID := range tasks[i].LabelSet() {
+			labels = append(labels, domain.TaskLabel{TaskID: tasks[i].ID, LabelID: labelID})
+		}
+	}
+	if len(assignees) > 0 {
+		if err := conn(ctx, r.db).CreateInBatches(assignees, 500).Error; err != nil {
+			return err
+		}
+	}
+	if len(labels) > 0 {
+		return conn(ctx, r.db).CreateInBatches(labels, 500).Error
+	}
+	return nil
+}
+
+// orderLabels preloads a task's labels by name.
+func orderLabels(db *gorm.DB) *gorm.DB {
+	return db.Order("labels.name, labels.id")
 }
 
 func (r *taskRepository) GetByID(ctx context.Context, id uint) (*domain.Task, error) {
 	var task domain.Task
-	err := r.db.WithContext(ctx).Preload("Assignee").First(&task, id).Error
+	err := conn(ctx, r.db).Preload("Assignee").Preload("Assignees").Preload("Watchers").Preload("Labels", orderLabels).Preload("CustomFields").First(&task, id).Error
 	return &task, err
 }
 
-func (r *taskRepository) GetByProjectID(ctx context.Context, projectID uint) ([]domain.Task, error) {
+func (r *taskRepository) GetByProjectID(ctx context.Context, projectID uint, query domain.TaskQuery) (*domain.TaskPage, error) {
+	return listTasks(conn(ctx, r.db).Where("tasks.project_id = ?", projectID), query)
+}
+
+// applyTaskFilter adds the filter's conditions to a query on tasks.
+func applyTaskFilter(db *gorm.DB, filter domain.TaskFilter) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		db = db.Where("tasks.status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		db = db.Where("tasks.priority IN ?", filter.Priorities)
+	}
+	// Any assignment counts, not just the primary assignee
+	switch {
+	case len(filter.AssigneeIDs) > 0 && filter.Unassigned:
+		db = db.Where("(EXISTS ("+assignedTo+") OR NOT EXISTS ("+assigned+"))", filter.AssigneeIDs)
+	case len(filter.AssigneeIDs) > 0:
+		db = db.Where("EXISTS ("+assignedTo+")", filter.AssigneeIDs)
+	case filter.Unassigned:
+		db = db.Where("NOT EXISTS (" + assigned + ")")
+	}
+	if len(filter.LabelIDs) > 0 {
+		db = db.Where("EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id IN ?)", filter.LabelIDs)
+	}
+
+	ranges := []struct {
+		column   string
+		from, to *time.Time
+	}{
+		{"tasks.due_date", filter.DueFrom, filter.DueTo},
+		{"tasks.created_at", filter.CreatedFrom, filter.CreatedTo},
+		{"tasks.updated_at", filter.UpdatedFrom, filter.UpdatedTo},
+	}
+	for _, rg := range ranges {
+		if rg.from != nil {
+			db = db.Where(rg.column+" >= ?", *rg.from)
+		}
+		if rg.to != nil {
+			db = db.Where(rg.column+" < ?", *rg.to)
+		}
+	}
+
+	if filter.Text != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Text) + "%"
+		db = db.Where("(tasks.title ILIKE ? OR tasks.description ILIKE ?)", pattern, pattern)
+	}
+
+	for _, cf := range filter.CustomFields {
+		if cf.Type == domain.CustomFieldMultiSelect {
+			db = db.Where(`EXISTS (SELECT 1 FROM custom_field_values v
+				WHERE v.task_id = tasks.id AND v.field_id = ? AND v.value @> jsonb_build_array(?::text))`, cf.FieldID, cf.Value)
+		} else {
+			db = db.Where(`EXISTS (SELECT 1 FROM custom_field_values v
+				WHERE v.task_id = tasks.id AND v.field_id = ? AND v.value #>> '{}' = ?)`, cf.FieldID, cf.Value)
+		}
+	}
+	return db
+}
+
+const (
+	assigned   = "SELECT 1 FROM task_assignees ta WHERE ta.task_id = tasks.id"
+	assignedTo = assigned + " AND ta.user_id IN ?"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// taskStatusRank orders the built-in statuses along the default workflow;
+// any other status sorts after them.
+var taskStatusRank = fmt.Sprintf("(CASE tasks.status WHEN '%s' THEN 0 WHEN '%s' THEN 1 WHEN '%s' THEN 2 WHEN '%s' THEN 3 ELSE 4 END)",
+	domain.TaskStatusNotStarted, domain.TaskStatusInProgress, domain.TaskStatusOverdue, domain.TaskStatusCompleted)
+
+func statusRank(status domain.TaskStatus) int {
+	switch status {
+	case domain.TaskStatusNotStarted:
+		return 0
+	case domain.TaskStatusInProgress:
+		return 1
+	case domain.TaskStatusOverdue:
+		return 2
+	case domain.TaskStatusCompleted:
+		return 3
+	}
+	return 4
+}
+
+// taskPriorityRank orders priorities from most to least urgent.
+var taskPriorityRank = fmt.Sprintf("(CASE tasks.priority WHEN '%s' THEN 0 WHEN '%s' THEN 1 WHEN '%s' THEN 2 ELSE 3 END)",
+	domain.TaskPriorityUrgent, domain.TaskPriorityHigh, domain.TaskPriorityMedium)
+
+func priorityRank(priority domain.TaskPriority) int {
+	switch priority {
+	case domain.TaskPriorityUrgent:
+		return 0
+	case domain.TaskPriorityHigh:
+		return 1
+	case domain.TaskPriorityMedium:
+		return 2
+	}
+	return 3
+}
+
+// taskCursor is the position after the last task of a page: its sort value
+// and ID, plus the sort it was taken under.
+type taskCursor struct {
+	Field domain.TaskSortField `json:"f"`
+	Desc  bool                 `json:"d"`
+	Value json.RawMessage      `json:"v"`
+	ID    uint                 `json:"id"`
+}
+
+func sortExpression(field domain.TaskSortField) string {
+	switch field {
+	case domain.TaskSortDueDate:
+		return "tasks.due_date"
+	case domain.TaskSortStatus:
+		return taskStatusRank
+	case domain.TaskSortUpdated:
+		return "tasks.updated_at"
+	case domain.TaskSortPriority:
+		return taskPriorityRank
+	case domain.TaskSortRank:
+		return "tasks.rank"
+	}
+	return "tasks.created_at"
+}
+
+func sortValue(task *domain.Task, field domain.TaskSortField) interface{} {
+	switch field {
+	case domain.TaskSortDueDate:
+		return task.DueDate
+	case domain.TaskSortStatus:
+		return statusRank(task.Status)
+	case domain.TaskSortUpdated:
+		return task.UpdatedAt
+	case domain.TaskSortPriority:
+		return priorityRank(task.Priority)
+	case domain.TaskSortRank:
+		return task.Rank
+	}
+	return task.CreatedAt
+}
+
+func encodeTaskCursor(task *domain.Task, sort domain.TaskSort) string {
+	value, _ := json.Marshal(sortValue(task, sort.Field))
+	b, _ := json.Marshal(taskCursor{Field: sort.Field, Desc: sort.Desc, Value: value, ID: task.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTaskCursor(cursor string, sort domain.TaskSort) (interface{}, uint, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", domain.ErrInvalidInput)
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	var tc taskCursor
+	if err := json.Unmarshal(b, &tc); err != nil {
+		return nil, 0, invalid
+	}
+	if tc.Field != sort.Field || tc.Desc != sort.Desc {
+		return nil, 0, fmt.Errorf("%w: cursor was issued for a different sort", domain.ErrInvalidInput)
+	}
+
+	switch sort.Field {
+	case domain.TaskSortStatus, domain.TaskSortPriority:
+		var rank int
+		if err := json.Unmarshal(tc.Value, &rank); err != nil {
+			return nil, 0, invalid
+		}
+		return rank, tc.ID, nil
+	case domain.TaskSortRank:
+		var rank string
+		if err := json.Unmarshal(tc.Value, &rank); err != nil {
+			return nil, 0, invalid
+		}
+		return rank, tc.ID, nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(tc.Value, &t); err != nil {
+		return nil, 0, invalid
+	}
+	return t, tc.ID, nil
+}
+
+// listTasks runs a task listing on db, applying the query's filter, sort and
+// keyset pagination.
+func listTasks(db *gorm.DB, query domain.TaskQuery) (*domain.TaskPage, error) {
+	db = applyTaskFilter(db, query.Filter)
+
+	expr := sortExpression(query.Sort.Field)
+	dir, cmp := "ASC", ">"
+	if query.Sort.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if query.Cursor != "" {
+		value, id, err := decodeTaskCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND tasks.id %[2]s ?))", expr, cmp), value, value, id)
+	}
+	db = db.Order(fmt.Sprintf("%s %s, tasks.id %s", expr, dir, dir))
+	if query.Limit > 0 {
+		// One extra row tells us whether another page follows
+		db = db.Limit(query.Limit + 1)
+	}
+
 	var tasks []domain.Task
-	err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Preload("Assignee").Find(&tasks).Error
-	return tasks, err
+	if err := db.Preload("Assignee").Preload("Assignees").Preload("Labels", orderLabels).Preload("CustomFields").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	page := &domain.TaskPage{Tasks: tasks}
+	if query.Limit > 0 && len(tasks) > query.Limit {
+		page.Tasks = tasks[:query.Limit]
+		page.NextCursor = encodeTaskCursor(&page.Tasks[query.Limit-1], query.Sort)
+	}
+	return page, nil
 }
 
 func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
 	// Optimistic Locking
-	result := r.db.WithContext(ctx).Model(&domain.Task{}).
+	result := conn(ctx, r.db).Model(&domain.Task{}).
 		Where("id = ? AND version = ?", task.ID, task.Version).
 		Updates(map[string]interface{}{
-			"title":       task.Title,
-			"description": task.Description,
-			"status":      task.Status,
-			"due_date":    task.DueDate,
-			"assignee_id": task.AssigneeID,
-			"version":     task.Version + 1,
-			"updated_at":  time.Now(),
+			"title":             task.Title,
+			"description":       task.Description,
+			"status":            task.Status,
+			"priority":          task.Priority,
+			"due_date":          task.DueDate,
+			"start_date":        task.StartDate,
+			"duration_days":     task.DurationDays,
+			"estimate_minutes":  task.EstimateMinutes,
+			"remaining_minutes": task.RemainingMinutes,
+			"assignee_id":       task.AssigneeID,
+			"parent_id":         task.ParentID,
+			"version":           task.Version + 1,
+			"updated_at":        time.Now(),
 		})
 
 	if result.Error != nil {
@@ -56,35 +323,481 @@ func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
 	return nil
 }
 
-func (r *taskRepository) MarkAsOverdue(ctx context.Context) error {
-	// Atomic Update for Scheduler
-	return r.db.WithContext(ctx).Model(&domain.Task{}).
-		Where("due_date < ? AND status != ? AND status != ?", time.Now(), domain.TaskStatusCompleted, domain.TaskStatusOverdue).
+func (r *taskRepository) MoveToProject(ctx context.Context, task *domain.Task) error {
+	result := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("id = ? AND version = ?", task.ID, task.Version).
 		Updates(map[string]interface{}{
-			"status":     domain.TaskStatusOverdue,
+			"project_id": task.ProjectID,
+			"status":     task.Status,
+			"rank":       task.Rank,
+			"parent_id":  task.ParentID,
+			"sprint_id":  nil,
+			"version":    task.Version + 1,
 			"updated_at": time.Now(),
-		}).Error
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *taskRepository) AddLoggedMinutes(ctx context.Context, id uint, minutes int) error {
+	result := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"logged_minutes": gorm.Expr("GREATEST(logged_minutes + ?, 0)", minutes),
+			// GREATEST skips nulls, so tasks without an estimate need a guard
+			"remaining_minutes": gorm.Expr("CASE WHEN remaining_minutes IS NULL THEN NULL ELSE GREATEST(remaining_minutes - ?, 0) END", minutes),
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *taskRepository) MarkAsOverdue(ctx context.Context) ([]domain.TaskStatusChange, error) {
+	// Atomic Update for Scheduler. The subquery keeps each task's previous
+	// status for the activity history.
+	var rows []struct {
+		ID         uint
+		ProjectID  uint
+		FromStatus domain.TaskStatus
+	}
+	now := time.Now()
+	err := conn(ctx, r.db).Raw(`
+		UPDATE tasks SET status = ?, updated_at = ?
+		FROM (
+			SELECT id, status FROM tasks
+			WHERE due_date < ? AND status NOT IN (?, ?) AND deleted_at IS NULL
+			FOR UPDATE
+		) AS previous
+		WHERE tasks.id = previous.id
+		RETURNING tasks.id, tasks.project_id, previous.status AS from_status`,
+		domain.TaskStatusOverdue, now, now, domain.TaskStatusCompleted, domain.TaskStatusOverdue,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]domain.TaskStatusChange, len(rows))
+	for i, row := range rows {
+		changes[i] = domain.TaskStatusChange{
+			TaskID:    row.ID,
+			ProjectID: row.ProjectID,
+			From:      row.FromStatus,
+			To:        domain.TaskStatusOverdue,
+		}
+	}
+	return changes, nil
 }
 
 func (r *taskRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&domain.Task{}, id).Error
+	return conn(ctx, r.db).Delete(&domain.Task{}, id).Error
+}
+
+func (r *taskRepository) SetRecurrence(ctx context.Context, id uint, recurrenceID *uint) error {
+	return conn(ctx, r.db).Model(&domain.Task{}).Where("id = ?", id).Update("recurrence_id", recurrenceID).Error
+}
+
+func (r *taskRepository) SetAssignees(ctx context.Context, id uint, userIDs []uint) error {
+	db := conn(ctx, r.db)
+	remove := db.Where("task_id = ?", id)
+	if len(userIDs) > 0 {
+		remove = remove.Where("user_id NOT IN ?", userIDs)
+	}
+	if err := remove.Delete(&domain.TaskAssignee{}).Error; err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	rows := make([]domain.TaskAssignee, len(userIDs))
+	for i, userID := range userIDs {
+		rows[i] = domain.TaskAssignee{TaskID: id, UserID: userID}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func (r *taskRepository) SetLabels(ctx context.Context, id uint, labelIDs []uint) error {
+	db := conn(ctx, r.db)
+	remove := db.Where("task_id = ?", id)
+	if len(labelIDs) > 0 {
+		remove = remove.Where("label_id NOT IN ?", labelIDs)
+	}
+	if err := remove.Delete(&domain.TaskLabel{}).Error; err != nil {
+		return err
+	}
+	if len(labelIDs) == 0 {
+		return nil
+	}
+	rows := make([]domain.TaskLabel, len(labelIDs))
+	for i, labelID := range labelIDs {
+		rows[i] = domain.TaskLabel{TaskID: id, LabelID: labelID}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func (r *taskRepository) AddWatcher(ctx context.Context, id, userID uint) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.TaskWatcher{TaskID: id, UserID: userID}).Error
+}
+
+func (r *taskRepository) RemoveWatcher(ctx context.Context, id, userID uint) error {
+	return conn(ctx, r.db).Where("task_id = ? AND user_id = ?", id, userID).Delete(&domain.TaskWatcher{}).Error
+}
+
+func (r *taskRepository) LockRanks(ctx context.Context, projectID uint) error {
+	return conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext('task_rank:' || ?::text))", projectID).Error
+}
+
+func (r *taskRepository) GetLastRank(ctx context.Context, projectID uint) (string, error) {
+	var ranks []string
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("project_id = ?", projectID).
+		Order("rank DESC").Limit(1).Pluck("rank", &ranks).Error
+	if err != nil || len(ranks) == 0 {
+		return "", err
+	}
+	return ranks[0], nil
+}
+
+func (r *taskRepository) GetNeighborRank(ctx context.Context, projectID uint, rank string, id, excludeID uint, after bool) (string, bool, error) {
+	cmp, dir := "<", "DESC"
+	if after {
+		cmp, dir = ">", "ASC"
+	}
+	var ranks []string
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("project_id = ? AND id <> ?", projectID, excludeID).
+		Where(fmt.Sprintf("(rank %[1]s ? OR (rank = ? AND id %[1]s ?))", cmp), rank, rank, id).
+		Order(fmt.Sprintf("rank %[1]s, id %[1]s", dir)).Limit(1).Pluck("rank", &ranks).Error
+	if err != nil || len(ranks) == 0 {
+		return "", false, err
+	}
+	return ranks[0], true, nil
+}
+
+func (r *taskRepository) SetRank(ctx context.Context, id uint, rank string) error {
+	// Reordering is not an edit of the task, so the version stays as it is
+	return conn(ctx, r.db).Model(&domain.Task{}).Where("id = ?", id).
+		UpdateColumn("rank", rank).Error
+}
+
+func (r *taskRepository) GetRankOrder(ctx context.Context, projectID uint) ([]uint, error) {
+	var ids []uint
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("project_id = ?", projectID).
+		Order("rank, id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *taskRepository) SetRanks(ctx context.Context, ids []uint, ranks []string) error {
+	const chunk = 500
+	for start := 0; start < len(ids); start += chunk {
+		end := min(start+chunk, len(ids))
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, 2*(end-start))
+		for i := start; i < end; i++ {
+			values = append(values, "(?::bigint, ?::text)")
+			args = append(args, ids[i], ranks[i])
+		}
+		err := conn(ctx, r.db).Exec(`
+			UPDATE tasks SET rank = v.rank
+			FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, rank)
+			WHERE tasks.id = v.id`, args...).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *taskRepository) GetProjectsToRebalance(ctx context.Context, maxLength int) ([]uint, error) {
+	var ids []uint
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("rank = '' OR length(rank) > ?", maxLength).
+		Distinct("project_id").Pluck("project_id", &ids).Error
+	return ids, err
+}
+
+func (r *taskRepository) GetDeletedIDs(ctx context.Context, before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := conn(ctx, r.db).Unscoped().Model(&domain.Task{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *taskRepository) Purge(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db := conn(ctx, r.db)
+	comments := db.Unscoped().Model(&domain.Comment{}).Select("id").Where("task_id IN ?", ids)
+	// Each step runs only once the previous one succeeded
+	steps := []func() *gorm.DB{
+		func() *gorm.DB { return db.Where("comment_id IN (?)", comments).Delete(&domain.CommentMention{}) },
+		func() *gorm.DB { return db.Where("comment_id IN (?)", comments).Delete(&domain.CommentRevision{}) },
+		func() *gorm.DB { return db.Unscoped().Where("task_id IN ?", ids).Delete(&domain.Comment{}) },
+		func() *gorm.DB { return db.Where("task_id IN ?", ids).Delete(&domain.CustomFieldValue{}) },
+		func() *gorm.DB { return db.Where("task_id IN ?", ids).Delete(&domain.Notification{}) },
+		func() *gorm.DB { return db.Where("task_id IN ?", ids).Delete(&domain.TaskActivity{}) },
+		func() *gorm.DB { return db.Where("task_id IN ?", ids).Delete(&domain.TaskAssignee{}) },
+		func() *gorm.DB { return db.Where("task_id IN ?", ids).Delete(&domain.TaskWatcher{}) },
+		func() *gorm.DB { return db.Where("task_id IN ?", ids).Delete(&domain.TaskLabel{}) },
+		func() *gorm.DB {
+			return db.Where("blocker_id IN ? OR blocked_id IN ?", ids, ids).Delete(&domain.TaskDependency{})
+		},
+		// Subtasks deleted before their parent still point at it
+		func() *gorm.DB {
+			return db.Unscoped().Model(&domain.Task{}).Where("parent_id IN ?", ids).Update("parent_id", nil)
+		},
+		func() *gorm.DB {
+			return db.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&domain.Task{})
+		},
+	}
+	for _, step := range steps {
+		if err := step().Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *taskRepository) GetOverdueTasks(ctx context.Context) ([]domain.Task, error) {
 	var tasks []domain.Task
 	now := time.Now()
-	err := r.db.WithContext(ctx).Where("due_date < ? AND status != ?", now, domain.TaskStatusCompleted).Find(&tasks).Error
+	err := conn(ctx, r.db).Where("due_date < ? AND status != ?", now, domain.TaskStatusCompleted).Find(&tasks).Error
 	return tasks, err
 }
 
-func (r *taskRepository) GetByAssigneeID(ctx context.Context, assigneeID uint) ([]domain.Task, error) {
-	var tasks []domain.Task
-	err := r.db.WithContext(ctx).Where("assignee_id = ?", assigneeID).Preload("Assignee").Find(&tasks).Error
-	return tasks, err
+func (r *taskRepository) GetByAssigneeID(ctx context.Context, assigneeID uint, query domain.TaskQuery) (*domain.TaskPage, error) {
+	return listTasks(conn(ctx, r.db).Where("EXISTS ("+assignedTo+")", []uint{assigneeID}), query)
+}
+
+func (r *taskRepository) GetByProjectIDAndAssigneeID(ctx context.Context, projectID uint, assigneeID uint, query domain.TaskQuery) (*domain.TaskPage, error) {
+	return listTasks(conn(ctx, r.db).Where("tasks.project_id = ? AND EXISTS ("+assignedTo+")", projectID, []uint{assigneeID}), query)
+}
+
+func (r *taskRepository) GetProjectStats(ctx context.Context, projectID uint, now, dueSoonUntil time.Time, dueSoonAssigneeID *uint) (*domain.ProjectStats, error) {
+	stats := &domain.ProjectStats{
+		ProjectID: projectID,
+		ByStatus:  make(map[domain.TaskStatus]int64),
+	}
+	db := conn(ctx, r.db)
+
+	var statusCounts []struct {
+		Status domain.TaskStatus
+		Count  int64
+	}
+	err := db.Model(&domain.Task{}).
+		Select("status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).
+		Group("status").
+		Scan(&statusCounts).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range statusCounts {
+		stats.ByStatus[sc.Status] = sc.Count
+		stats.Total += sc.Count
+	}
+
+	err = db.Model(&domain.Task{}).
+		Where("project_id = ? AND due_date < ? AND status != ?", projectID, now, domain.TaskStatusCompleted).
+		Count(&stats.Overdue).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Table("tasks").
+		Select(`tasks.assignee_id, COALESCE(users.name, '') AS name, COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE tasks.status = ?) AS completed,
+			COUNT(*) FILTER (WHERE tasks.due_date < ? AND tasks.status != ?) AS overdue`,
+			domain.TaskStatusCompleted, now, domain.TaskStatusCompleted).
+		Joins("LEFT JOIN users ON users.id = tasks.assignee_id").
+		Where("tasks.project_id = ? AND tasks.deleted_at IS NULL", projectID).
+		Group("tasks.assignee_id, users.name").
+		Order("total DESC").
+		Scan(&stats.ByAssignee).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dueSoon := db.Where("project_id = ? AND due_date >= ? AND due_date < ? AND status != ?", projectID, now, dueSoonUntil, domain.TaskStatusCompleted)
+	if dueSoonAssigneeID != nil {
+		dueSoon = dueSoon.Where("EXISTS ("+assignedTo+")", []uint{*dueSoonAssigneeID})
+	}
+	err = dueSoon.Order("due_date ASC").Preload("Assignee").Find(&stats.DueSoon).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *taskRepository) GetStatusCounts(ctx context.Context, projectID uint) (map[domain.TaskStatus]int64, error) {
+	var rows []struct {
+		Status domain.TaskStatus
+		Count  int64
+	}
+	err := conn(ctx, r.db).Model(&domain.Task{}).
+		Select("status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.TaskStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *taskRepository) SetSprint(ctx context.Context, projectID uint, taskIDs []uint, sprintID *uint) (int64, error) {
+	result := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("project_id = ? AND id IN ?", projectID, taskIDs).
+		Updates(map[string]interface{}{
+			"sprint_id":  sprintID,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *taskRepository) MoveUnfinishedFromSprint(ctx context.Context, fromSprintID uint, toSprintID *uint) (int64, error) {
+	result := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("sprint_id = ? AND status != ?", fromSprintID, domain.TaskStatusCompleted).
+		Updates(map[string]interface{}{
+			"sprint_id":  toSprintID,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *taskRepository) ClearSprint(ctx context.Context, sprintID uint) error {
+	return conn(ctx, r.db).Model(&domain.Task{}).
+		Where("sprint_id = ?", sprintID).
+		Updates(map[string]interface{}{
+			"sprint_id":  nil,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *taskRepository) GetAncestorIDs(ctx context.Context, id uint) ([]uint, error) {
+	var ids []uint
+	// The depth guard stops the walk should a cycle ever slip into the data
+	err := conn(ctx, r.db).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM tasks WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, t.parent_id, a.depth + 1 FROM tasks t
+			JOIN ancestors a ON t.id = a.parent_id
+			WHERE t.deleted_at IS NULL AND a.depth < ?
+		)
+		SELECT id FROM ancestors WHERE depth > 0 ORDER BY depth`, id, domain.MaxTaskDepth*2).
+		Scan(&ids).Error
+	return ids, err
 }
 
-func (r *taskRepository) GetByProjectIDAndAssigneeID(ctx context.Context, projectID uint, assigneeID uint) ([]domain.Task, error) {
+func (r *taskRepository) GetSubtree(ctx context.Context, rootID uint, maxDepth int) ([]domain.TaskNode, error) {
+	db := conn(ctx, r.db)
+
+	var levels []struct {
+		ID    uint
+		Depth int
+	}
+	err := db.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id, 0 AS depth FROM tasks WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, s.depth + 1 FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL AND s.depth < ?
+		)
+		SELECT id, depth FROM subtree ORDER BY depth, id`, rootID, maxDepth).
+		Scan(&levels).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	ids := make([]uint, len(levels))
+	for i, level := range levels {
+		ids[i] = level.ID
+	}
 	var tasks []domain.Task
-	err := r.db.WithContext(ctx).Where("project_id = ? AND assignee_id = ?", projectID, assigneeID).Preload("Assignee").Find(&tasks).Error
-	return tasks, err
+	if err := db.Where("id IN ?", ids).Preload("Assignee").Preload("Assignees").Preload("Labels", orderLabels).Preload("CustomFields").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]domain.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	nodes := make([]domain.TaskNode, 0, len(levels))
+	for _, level := range levels {
+		if task, ok := byID[level.ID]; ok {
+			nodes = append(nodes, domain.TaskNode{Task: task, Depth: level.Depth})
+		}
+	}
+	return nodes, nil
+}
+
+func (r *taskRepository) GetSubtaskProgress(ctx context.Context, parentIDs []uint) (map[uint]domain.SubtaskProgress, error) {
+	progress := make(map[uint]domain.SubtaskProgress)
+	if len(parentIDs) == 0 {
+		return progress, nil
+	}
+
+	var rows []struct {
+		RootID    uint
+		Total     int64
+		Completed int64
+	}
+	err := conn(ctx, r.db).Raw(`
+		WITH RECURSIVE descendants AS (
+			SELECT parent_id AS root_id, id, status, 1 AS depth FROM tasks
+			WHERE parent_id IN ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT d.root_id, t.id, t.status, d.depth + 1 FROM tasks t
+			JOIN descendants d ON t.parent_id = d.id
+			WHERE t.deleted_at IS NULL AND d.depth < ?
+		)
+		SELECT root_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE status = ?) AS completed
+		FROM descendants GROUP BY root_id`, parentIDs, domain.MaxTaskDepth, domain.TaskStatusCompleted).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		progress[row.RootID] = domain.SubtaskProgress{Total: row.Total, Completed: row.Completed}
+	}
+	return progress, nil
+}
+
+func (r *taskRepository) SetParent(ctx context.Context, id uint, parentID *uint) error {
+	return conn(ctx, r.db).Model(&domain.Task{}).
+		Where("id = ?", id).
+		Update("parent_id", parentID).Error
+}
+
+func (r *taskRepository) ReparentChildren(ctx context.Context, parentID uint, newParentID *uint) error {
+	return conn(ctx, r.db).Model(&domain.Task{}).
+		Where("parent_id = ?", parentID).
+		Updates(map[string]interface{}{
+			"parent_id":  newParentID,
+			"updated_at": time.Now(),
+		}).Error
 }
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+func TestTaskCursorRoundTrip(t *testing.T) {
+	created := time.Date(2026, 4, 1, 9, 30, 15, 123456000, time.UTC)
+	task := &domain.Task{
+		ID:        42,
+		Status:    domain.TaskStatusOverdue,
+		Priority:  domain.TaskPriorityHigh,
+		Rank:      "a1i",
+		DueDate:   created.AddDate(0, 0, 7),
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+	}
+
+	tests := []struct {
+		sort domain.TaskSort
+		want interface{}
+	}{
+		{domain.TaskSort{Field: domain.TaskSortCreated}, created},
+		{domain.TaskSort{Field: domain.TaskSortDueDate, Desc: true}, task.DueDate},
+		{domain.TaskSort{Field: domain.TaskSortUpdated}, task.UpdatedAt},
+		{domain.TaskSort{Field: domain.TaskSortStatus}, 2},
+		{domain.TaskSort{Field: domain.TaskSortPriority, Desc: true}, 1},
+		{domain.TaskSort{Field: domain.TaskSortRank}, "a1i"},
+	}
+	for _, tt := range tests {
+		cursor := encodeTaskCursor(task, tt.sort)
+		value, id, err := decodeTaskCursor(cursor, tt.sort)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.sort.Field, err)
+		}
+		if id != task.ID {
+			t.Errorf("%s: id = %d, want %d", tt.sort.Field, id, task.ID)
+		}
+		if want, ok := tt.want.(time.Time); ok {
+			if got, _ := value.(time.Time); !got.Equal(want) {
+				t.Errorf("%s: value = %v, want %v", tt.sort.Field, value, want)
+			}
+		} else if value != tt.want {
+			t.Errorf("%s: value = %v, want %v", tt.sort.Field, value, tt.want)
+		}
+	}
+}
+
+func TestDecodeTaskCursorInvalid(t *testing.T) {
+	task := &domain.Task{ID: 1, Rank: "i"}
+	rankCursor := encodeTaskCursor(task, domain.TaskSort{Field: domain.TaskSortRank})
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   domain.TaskSort
+	}{
+		{"not base64", "%%%", domain.TaskSort{Field: domain.TaskSortRank}},
+		{"not JSON", "bm90IGpzb24", domain.TaskSort{Field: domain.TaskSortRank}},
+		{"other field", rankCursor, domain.TaskSort{Field: domain.TaskSortCreated}},
+		{"other direction", rankCursor, domain.TaskSort{Field: domain.TaskSortRank, Desc: true}},
+	}
+	for _, tt := range tests {
+		if _, _, err := decodeTaskCursor(tt.cursor, tt.sort); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%s: error = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
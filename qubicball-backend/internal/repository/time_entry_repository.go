@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type timeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewTimeEntryRepository(db *gorm.DB) domain.TimeEntryRepository {
+	return &timeEntryRepository{db}
+}
+
+func (r *timeEntryRepository) Create(ctx context.Context, entry *domain.TimeEntry) error {
+	return conn(ctx, r.db).Omit("User").Create(entry).Error
+}
+
+func (r *timeEntryRepository) GetByID(ctx context.Context, id uint) (*domain.TimeEntry, error) {
+	var entry domain.TimeEntry
+	err := conn(ctx, r.db).Preload("User").First(&entry, id).Error
+	return &entry, err
+}
+
+func (r *timeEntryRepository) GetByTaskID(ctx context.Context, taskID uint) ([]domain.TimeEntry, error) {
+	var entries []domain.TimeEntry
+	err := conn(ctx, r.db).Where("task_id = ?", taskID).
+		Order("started_at DESC, id DESC").
+		Preload("User").Find(&entries).Error
+	return entries, err
+}
+
+func (r *timeEntryRepository) Update(ctx context.Context, entry *domain.TimeEntry) error {
+	result := conn(ctx, r.db).Model(&domain.TimeEntry{}).
+		Where("id = ?", entry.ID).
+		Updates(map[string]interface{}{
+			"started_at": entry.StartedAt,
+			"minutes":    entry.Minutes,
+			"note":       entry.Note,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *timeEntryRepository) Delete(ctx context.Context, id uint) error {
+	result := conn(ctx, r.db).Delete(&domain.TimeEntry{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *timeEntryRepository) GetReport(ctx context.Context, query domain.TimeReportQuery) ([]domain.TimeReportRow, error) {
+	// Deleted users, projects and tasks keep their labels
+	var selects, joins, group, order string
+	switch query.GroupBy {
+	case domain.TimeReportByUser:
+		selects = "te.user_id AS id, COALESCE(u.name, '') AS label"
+		joins = "LEFT JOIN users u ON u.id = te.user_id"
+		group, order = "te.user_id, u.name", "label, id"
+	case domain.TimeReportByProject:
+		selects = "te.project_id AS id, COALESCE(p.name, '') AS label"
+		joins = "LEFT JOIN projects p ON p.id = te.project_id"
+		group, order = "te.project_id, p.name", "label, id"
+	case domain.TimeReportByTask:
+		selects = "te.task_id AS id, COALESCE(t.title, '') AS label"
+		joins = "LEFT JOIN tasks t ON t.id = te.task_id"
+		group, order = "te.task_id, t.title", "label, id"
+	case domain.TimeReportByDay:
+		selects = "to_char(te.started_at, 'YYYY-MM-DD') AS date, to_char(te.started_at, 'YYYY-MM-DD') AS label"
+		group, order = "1, 2", "date"
+	default:
+		return nil, fmt.Errorf("%w: invalid group_by %q", domain.ErrInvalidInput, query.GroupBy)
+	}
+
+	db := conn(ctx, r.db).Table("time_entries te").
+		Select(selects+", SUM(te.minutes) AS minutes").
+		Where("te.started_at >= ? AND te.started_at < ?", query.From, query.To.AddDate(0, 0, 1))
+	if joins != "" {
+		db = db.Joins(joins)
+	}
+	if query.ProjectID != nil {
+		db = db.Where("te.project_id = ?", *query.ProjectID)
+	}
+	if query.UserID != nil {
+		db = db.Where("te.user_id = ?", *query.UserID)
+	}
+
+	var rows []domain.TimeReportRow
+	err := db.Group(group).Order(order).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *timeEntryRepository) GetTimer(ctx context.Context, userID uint) (*domain.RunningTimer, error) {
+	var timer domain.RunningTimer
+	err := conn(ctx, r.db).First(&timer, "user_id = ? AND stopped_at IS NULL", userID).Error
+	return &timer, err
+}
+
+func (r *timeEntryRepository) StopTimer(ctx context.Context, timer *domain.RunningTimer) error {
+	stopped := *timer
+	now := time.Now()
+	stopped.StoppedAt = &now
+	stopped.UpdatedAt = now
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stopped_at", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "running_timers.started_at <= excluded.started_at"},
+		}},
+	}).Create(&stopped).Error
+}
+
+func (r *timeEntryRepository) SaveTimers(ctx context.Context, timers []domain.RunningTimer) error {
+	if len(timers) == 0 {
+		return nil
+	}
+	// Timers Redis no longer has are kept: they may have been lost rather
+	// than stopped. A stopped timer is only replaced by a newer one.
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"task_id", "project_id", "started_at", "note", "stopped_at", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: `running_timers.started_at < excluded.started_at OR
+				(running_timers.started_at = excluded.started_at AND running_timers.stopped_at IS NULL)`},
+		}},
+	}).Create(&timers).Error
+}
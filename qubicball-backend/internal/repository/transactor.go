@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) domain.Transactor {
+	return &transactor{db}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Nested calls join the outer transaction
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction bound to ctx, or db when there is none.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 
 	"qubicball-backend/internal/domain"
 
@@ -42,3 +43,40 @@ func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
 	err := r.db.WithContext(ctx).Find(&users).Error
 	return users, err
 }
+
+func (r *userRepository) GetByEmails(ctx context.Context, emails []string) ([]domain.User, error) {
+	var users []domain.User
+	if len(emails) == 0 {
+		return users, nil
+	}
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	err := conn(ctx, r.db).Where("LOWER(email) IN ?", lowered).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) GetByIDs(ctx context.Context, ids []uint) ([]domain.User, error) {
+	var users []domain.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) GetByHandles(ctx context.Context, handles []string) ([]domain.User, error) {
+	var users []domain.User
+	if len(handles) == 0 {
+		return users, nil
+	}
+	lowered := make([]string, len(handles))
+	for i, handle := range handles {
+		lowered[i] = strings.ToLower(handle)
+	}
+	err := conn(ctx, r.db).
+		Where("LOWER(email) IN ? OR LOWER(split_part(email, '@', 1)) IN ?", lowered, lowered).
+		Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Attachment types are sniffed from the content rather than taken from the
+// client. Some formats sniff as a more generic container, so the declared
+// type is kept when it refines the sniffed one.
+var (
+	allowedAttachmentTypes = map[string]bool{
+		"image/png":       true,
+		"image/jpeg":      true,
+		"image/gif":       true,
+		"image/webp":      true,
+		"application/pdf": true,
+		"text/plain":      true,
+		"application/zip": true,
+	}
+	refinedAttachmentTypes = map[string][]string{
+		"text/plain": {"text/csv", "text/markdown", "application/json"},
+		"application/zip": {
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		},
+	}
+)
+
+type attachmentUsecase struct {
+	attachmentRepo domain.AttachmentRepository
+	taskRepo       domain.TaskRepository
+	blobStore      domain.BlobStore
+	transactor     domain.Transactor
+	contextTimeout time.Duration
+}
+
+func NewAttachmentUsecase(attachmentRepo domain.AttachmentRepository, taskRepo domain.TaskRepository, blobStore domain.BlobStore, transactor domain.Transactor, timeout time.Duration) domain.AttachmentUsecase {
+	return &attachmentUsecase{
+		attachmentRepo: attachmentRepo,
+		taskRepo:       taskRepo,
+		blobStore:      blobStore,
+		transactor:     transactor,
+		contextTimeout: timeout,
+	}
+}
+
+// attachmentContentType sniffs the upload's type and checks it is allowed.
+func attachmentContentType(content io.ReadSeeker, declared string) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !allowedAttachmentTypes[sniffed] {
+		return "", fmt.Errorf("%w: files of type %s cannot be attached", domain.ErrInvalidInput, sniffed)
+	}
+	declared, _, _ = mime.ParseMediaType(declared)
+	for _, refined := range refinedAttachmentTypes[sniffed] {
+		if declared == refined {
+			return declared, nil
+		}
+	}
+	return sniffed, nil
+}
+
+// maxBlobAttempts bounds how often an upload rewrites a blob that a
+// concurrent release removed before the upload's row referenced it.
+const maxBlobAttempts = 3
+
+// errBlobReleased reports that an upload's blob was removed before its row
+// was inserted.
+var errBlobReleased = errors.New("attachment blob was released during the upload")
+
+func attachmentStorageKey(checksum string) string {
+	return "sha256/" + checksum[:2] + "/" + checksum
+}
+
+func (u *attachmentUsecase) Upload(c context.Context, taskID uint, upload domain.AttachmentUpload) (*domain.Attachment, bool, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: uploads require an authenticated user", domain.ErrForbidden)
+	}
+	fileName := filepath.Base(strings.ReplaceAll(strings.TrimSpace(upload.FileName), `\`, "/"))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return nil, false, fmt.Errorf("%w: file name is required", domain.ErrInvalidInput)
+	}
+	if len(fileName) > 255 {
+		return nil, false, fmt.Errorf("%w: file name must be at most 255 bytes", domain.ErrInvalidInput)
+	}
+	if upload.Size <= 0 {
+		return nil, false, fmt.Errorf("%w: file is empty", domain.ErrInvalidInput)
+	}
+	if upload.Size > domain.MaxAttachmentSize {
+		return nil, false, fmt.Errorf("%w: files must be at most %d MB", domain.ErrInvalidInput, domain.MaxAttachmentSize>>20)
+	}
+	if _, err := u.taskRepo.GetByID(ctx, taskID); err != nil {
+		return nil, false, err
+	}
+	contentType, err := attachmentContentType(upload.Content, upload.ContentType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, io.LimitReader(upload.Content, domain.MaxAttachmentSize+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if size != upload.Size {
+		return nil, false, fmt.Errorf("%w: file size does not match its content", domain.ErrInvalidInput)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	existing, err := u.attachmentRepo.GetByTaskAndChecksum(ctx, taskID, checksum)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	attachment := &domain.Attachment{
+		TaskID:      taskID,
+		UploaderID:  actor.UserID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+		StorageKey:  attachmentStorageKey(checksum),
+	}
+	// The blob is written first and outside any transaction, since a large
+	// upload can be slow; its key is content-addressed, so writing it again
+	// is harmless. Under the blob lock the row is only inserted if a
+	// concurrent release hasn't removed the blob in between.
+	for attempt := 1; ; attempt++ {
+		if err := u.putBlob(c, attachment.StorageKey, upload.Content, size, contentType); err != nil {
+			return nil, false, err
+		}
+		err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := u.attachmentRepo.LockBlob(ctx, attachment.StorageKey); err != nil {
+				return err
+			}
+			exists, err := u.blobStore.Exists(ctx, attachment.StorageKey)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return errBlobReleased
+			}
+			return u.attachmentRepo.Create(ctx, attachment)
+		})
+		if !errors.Is(err, errBlobReleased) || attempt == maxBlobAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	created, err := u.attachmentRepo.GetByID(ctx, attachment.ID)
+	return created, true, err
+}
+
+// putBlob stores content under key unless the store already has it. It
+// runs under the request's context rather than the usecase timeout, as
+// uploads take as long as the client needs.
+func (u *attachmentUsecase) putBlob(ctx context.Context, key string, content io.ReadSeeker, size int64, contentType string) error {
+	exists, err := u.blobStore.Exists(ctx, key)
+	if err != nil || exists {
+		return err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return u.blobStore.Put(ctx, key, content, size, contentType)
+}
+
+func (u *attachmentUsecase) GetByTaskID(c context.Context, taskID uint) ([]domain.Attachment, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.taskRepo.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return u.attachmentRepo.GetByTaskID(ctx, taskID)
+}
+
+func (u *attachmentUsecase) Open(c context.Context, taskID, id uint) (*domain.Attachment, io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	attachment, err := u.taskAttachment(ctx, taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The content is read after this returns, so it isn't bound by the
+	// usecase timeout
+	content, err := u.blobStore.Get(c, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (u *attachmentUsecase) Delete(c context.Context, taskID, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	attachment, err := u.taskAttachment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || (actor.UserID != attachment.UploaderID && actor.Role == domain.RoleMember) {
+		return fmt.Errorf("%w: only the uploader, an admin or a manager can delete an attachment", domain.ErrForbidden)
+	}
+	if err := u.attachmentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	releaseBlobs(ctx, u.attachmentRepo, u.blobStore, u.transactor, attachment.StorageKey)
+	return nil
+}
+
+// taskAttachment loads an attachment, treating one on another task as
+// missing.
+func (u *attachmentUsecase) taskAttachment(ctx context.Context, taskID, id uint) (*domain.Attachment, error) {
+	attachment, err := u.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.TaskID != taskID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return attachment, nil
+}
+
+// releaseBlobs deletes the blobs no attachment refers to any more. Failures
+// are logged; an orphaned blob only costs storage.
+func releaseBlobs(ctx context.Context, repo domain.AttachmentRepository, store domain.BlobStore, transactor domain.Transactor, keys ...string) {
+	for _, key := range keys {
+		err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := repo.LockBlob(ctx, key); err != nil {
+				return err
+			}
+			count, err := repo.CountByStorageKey(ctx, key)
+			if err != nil || count > 0 {
+				return err
+			}
+			if err := store.Delete(ctx, key); err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to release attachment blob %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// boardCacheField holds the full board in the project's task cache hash,
+// so it is dropped along with the task listings.
+const boardCacheField = "board"
+
+type boardUsecase struct {
+	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewBoardUsecase(projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, redisClient *redis.Client, timeout time.Duration) domain.BoardUsecase {
+	return &boardUsecase{
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *boardUsecase) GetBoard(c context.Context, projectID uint, assigneeID *uint) (*domain.Board, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	// Only the unfiltered board is shared between users
+	cacheKey := projectTasksKey(projectID)
+	if assigneeID == nil {
+		if cached, err := u.redisClient.HGet(ctx, cacheKey, boardCacheField).Result(); err == nil {
+			var board domain.Board
+			if err := json.Unmarshal([]byte(cached), &board); err == nil {
+				return &board, nil
+			}
+		}
+	}
+
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	query := domain.TaskQuery{Sort: domain.TaskSort{Field: domain.TaskSortRank}}
+	var page *domain.TaskPage
+	if assigneeID != nil {
+		page, err = u.taskRepo.GetByProjectIDAndAssigneeID(ctx, projectID, *assigneeID, query)
+	} else {
+		page, err = u.taskRepo.GetByProjectID(ctx, projectID, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	counts, err := u.taskRepo.GetStatusCounts(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := project.EffectiveWorkflow()
+	board := &domain.Board{ProjectID: projectID, Columns: make([]domain.BoardColumn, len(workflow.Statuses))}
+	columns := make(map[domain.TaskStatus]*domain.BoardColumn, len(workflow.Statuses))
+	for i, status := range workflow.Statuses {
+		column := &board.Columns[i]
+		column.Status = status
+		column.WIPLimit = project.WIPLimits.Limit(status)
+		column.Count = counts[status]
+		column.OverLimit = column.WIPLimit != nil && column.Count > int64(*column.WIPLimit)
+		column.Cards = []domain.Task{}
+		columns[status] = column
+	}
+	// Cards arrive in rank order, which each column keeps
+	for _, task := range page.Tasks {
+		if column, ok := columns[task.Status]; ok {
+			column.Cards = append(column.Cards, task)
+		}
+	}
+
+	if assigneeID == nil {
+		jsonBoard, _ := json.Marshal(board)
+		u.redisClient.HSet(ctx, cacheKey, boardCacheField, jsonBoard)
+		u.redisClient.Expire(ctx, cacheKey, time.Minute*5)
+	}
+	return board, nil
+}
+
+func (u *boardUsecase) SetWIPLimits(c context.Context, projectID uint, limits domain.WIPLimits) (domain.WIPLimits, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return nil, fmt.Errorf("%w: only the project owner or an admin can change WIP limits", domain.ErrForbidden)
+	}
+	if err := limits.Validate(project.EffectiveWorkflow()); err != nil {
+		return nil, err
+	}
+	if len(limits) == 0 {
+		limits = nil
+	}
+
+	if err := u.projectRepo.UpdateWIPLimits(ctx, projectID, limits); err != nil {
+		return nil, err
+	}
+	u.redisClient.Del(ctx, fmt.Sprintf("project:%d", projectID))
+	bumpProjectListGeneration(ctx, u.redisClient)
+	invalidateTaskCaches(ctx, u.redisClient, projectID)
+
+	if limits == nil {
+		limits = domain.WIPLimits{}
+	}
+	return limits, nil
+}
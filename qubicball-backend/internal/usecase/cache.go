@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// projectListGenerationKey is bumped on every project write. Cached list
+// pages embed it in their keys.
+const projectListGenerationKey = "projects:gen"
+
+// bumpProjectListGeneration retires every cached project list page.
+func bumpProjectListGeneration(ctx context.Context, redisClient *redis.Client) {
+	redisClient.Incr(ctx, projectListGenerationKey)
+}
+
+// projectFavoritesGenerationKey is bumped when the user's favorites change.
+func projectFavoritesGenerationKey(userID uint) string {
+	return fmt.Sprintf("projects:favgen:%d", userID)
+}
+
+// parseGeneration reads a generation counter returned by MGET; missing
+// counters count as zero.
+func parseGeneration(v interface{}) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// projectStatsKey holds one hash field per due-soon window, so a single DEL
+// drops every cached variant when the project's tasks change.
+func projectStatsKey(projectID uint) string {
+	return fmt.Sprintf("project:%d:stats", projectID)
+}
+
+func projectTasksKey(projectID uint) string {
+	return fmt.Sprintf("tasks:project:%d", projectID)
+}
+
+// invalidateTaskCaches drops every cache derived from the projects' tasks.
+func invalidateTaskCaches(ctx context.Context, redisClient *redis.Client, projectIDs ...uint) {
+	for _, projectID := range projectIDs {
+		redisClient.Del(ctx, projectTasksKey(projectID), projectStatsKey(projectID))
+	}
+}
+
+// percent returns part/total as a percentage rounded to two decimals.
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
+func newCacheMetrics(hits, misses uint64) domain.CacheMetrics {
+	metrics := domain.CacheMetrics{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		metrics.HitRatio = math.Round(float64(hits)/float64(total)*10000) / 10000
+	}
+	return metrics
+}
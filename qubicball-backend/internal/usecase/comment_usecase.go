@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"qubicball-backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+const maxCommentLength = 10000
+
+type commentUsecase struct {
+	commentRepo      domain.CommentRepository
+	taskRepo         domain.TaskRepository
+	userRepo         domain.UserRepository
+	notificationRepo domain.NotificationRepository
+	transactor       domain.Transactor
+	contextTimeout   time.Duration
+}
+
+func NewCommentUsecase(commentRepo domain.CommentRepository, taskRepo domain.TaskRepository, userRepo domain.UserRepository, notificationRepo domain.NotificationRepository, transactor domain.Transactor, timeout time.Duration) domain.CommentUsecase {
+	return &commentUsecase{
+		commentRepo:      commentRepo,
+		taskRepo:         taskRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		transactor:       transactor,
+		contextTimeout:   timeout,
+	}
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: comment body is required", domain.ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", fmt.Errorf("%w: comment body must be at most %d characters", domain.ErrInvalidInput, maxCommentLength)
+	}
+	return body, nil
+}
+
+func (u *commentUsecase) Create(c context.Context, taskID uint, body string) (*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: comments require an authenticated user", domain.ErrForbidden)
+	}
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := u.resolveMentions(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &domain.Comment{
+		TaskID:   taskID,
+		AuthorID: actor.UserID,
+		Body:     body,
+		Mentions: mentions,
+	}
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.commentRepo.Create(ctx, comment); err != nil {
+			return err
+		}
+		return u.notifyMentions(ctx, actor.UserID, task, comment, mentions, taskFollowers(task))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.get(ctx, comment.ID)
+}
+
+func (u *commentUsecase) GetByTaskID(c context.Context, taskID uint) ([]domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.taskRepo.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	comments, err := u.commentRepo.GetByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		renderComment(&comments[i])
+	}
+	return comments, nil
+}
+
+func (u *commentUsecase) Update(c context.Context, taskID, id uint, body string) (*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := u.taskComment(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || actor.UserID != comment.AuthorID {
+		return nil, fmt.Errorf("%w: only the author can edit a comment", domain.ErrForbidden)
+	}
+	if body == comment.Body {
+		renderComment(comment)
+		return comment, nil
+	}
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := u.resolveMentions(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only users the edit newly mentions are notified
+	var added []domain.CommentMention
+	for _, mention := range mentions {
+		mentioned := false
+		for _, existing := range comment.Mentions {
+			if existing.UserID == mention.UserID {
+				mentioned = true
+				break
+			}
+		}
+		if !mentioned {
+			added = append(added, mention)
+		}
+	}
+
+	revision := &domain.CommentRevision{
+		CommentID:  comment.ID,
+		Body:       comment.Body,
+		EditedByID: actor.UserID,
+	}
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+	comment.Mentions = mentions
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.commentRepo.CreateRevision(ctx, revision); err != nil {
+			return err
+		}
+		if err := u.commentRepo.Update(ctx, comment); err != nil {
+			return err
+		}
+		return u.notifyMentions(ctx, actor.UserID, task, comment, added, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.get(ctx, comment.ID)
+}
+
+func (u *commentUsecase) Delete(c context.Context, taskID, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	comment, err := u.taskComment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || (actor.UserID != comment.AuthorID && actor.Role == domain.RoleMember) {
+		return fmt.Errorf("%w: only the author, an admin or a manager can delete a comment", domain.ErrForbidden)
+	}
+	return u.commentRepo.Delete(ctx, id)
+}
+
+func (u *commentUsecase) GetHistory(c context.Context, taskID, id uint) ([]domain.CommentRevision, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.taskComment(ctx, taskID, id); err != nil {
+		return nil, err
+	}
+	return u.commentRepo.GetRevisions(ctx, id)
+}
+
+// taskComment loads a comment, treating one on another task as missing.
+func (u *commentUsecase) taskComment(ctx context.Context, taskID, id uint) (*domain.Comment, error) {
+	comment, err := u.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.TaskID != taskID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (u *commentUsecase) get(ctx context.Context, id uint) (*domain.Comment, error) {
+	comment, err := u.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	renderComment(comment)
+	return comment, nil
+}
+
+// resolveMentions looks up the body's @handles. A handle matches a user's
+// email or the part before its @; handles matching no one, or more than one
+// user, are left as plain text.
+func (u *commentUsecase) resolveMentions(ctx context.Context, body string) ([]domain.CommentMention, error) {
+	handles := extractMentions(body)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+	users, err := u.userRepo.GetByHandles(ctx, handles)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string][]uint)
+	for _, user := range users {
+		email := strings.ToLower(user.Email)
+		matches[email] = append(matches[email], user.ID)
+		if local, _, found := strings.Cut(email, "@"); found && local != email {
+			matches[local] = append(matches[local], user.ID)
+		}
+	}
+
+	var mentions []domain.CommentMention
+	seen := make(map[uint]bool)
+	for _, handle := range handles {
+		ids := matches[handle]
+		if len(ids) != 1 || seen[ids[0]] {
+			continue
+		}
+		seen[ids[0]] = true
+		mentions = append(mentions, domain.CommentMention{UserID: ids[0], Handle: handle})
+	}
+	return mentions, nil
+}
+
+// notifyMentions tells the mentioned users about a comment, and followers,
+// the task's assignees and watchers, about a new one. The author is never
+// notified, and a mentioned follower only of the mention.
+func (u *commentUsecase) notifyMentions(ctx context.Context, authorID uint, task *domain.Task, comment *domain.Comment, mentions []domain.CommentMention, followers []uint) error {
+	if len(mentions) == 0 && len(followers) == 0 {
+		return nil
+	}
+	author, err := u.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return err
+	}
+
+	var notifications []domain.Notification
+	notified := map[uint]bool{authorID: true}
+	notify := func(userID uint, kind domain.NotificationType, message string) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		notifications = append(notifications, domain.Notification{
+			UserID:    userID,
+			Type:      kind,
+			ActorID:   &authorID,
+			TaskID:    &task.ID,
+			CommentID: &comment.ID,
+			Message:   message,
+		})
+	}
+	for _, mention := range mentions {
+		notify(mention.UserID, domain.NotificationMention, fmt.Sprintf("%s mentioned you on task %q", author.Name, task.Title))
+	}
+	for _, userID := range followers {
+		notify(userID, domain.NotificationComment, fmt.Sprintf("%s commented on task %q", author.Name, task.Title))
+	}
+	return u.notificationRepo.CreateBatch(ctx, notifications)
+}
+
+// taskFollowers returns the users following a task's comments: its
+// assignees and watchers.
+func taskFollowers(task *domain.Task) []uint {
+	followers := task.AssigneeSet()
+	for _, watcher := range task.Watchers {
+		if !containsID(followers, watcher.ID) {
+			followers = append(followers, watcher.ID)
+		}
+	}
+	return followers
+}
+
+func renderComment(comment *domain.Comment) {
+	mentions := make(map[string]uint, len(comment.Mentions))
+	for _, mention := range comment.Mentions {
+		mentions[strings.ToLower(mention.Handle)] = mention.UserID
+	}
+	comment.BodyHTML = renderMarkdown(comment.Body, mentions)
+}
@@ -0,0 +1,408 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type customFieldUsecase struct {
+	fieldRepo      domain.CustomFieldRepository
+	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	userRepo       domain.UserRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewCustomFieldUsecase(fieldRepo domain.CustomFieldRepository, projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, userRepo domain.UserRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.CustomFieldUsecase {
+	return &customFieldUsecase{
+		fieldRepo:      fieldRepo,
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		userRepo:       userRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+// authorize checks that the request's actor may manage the project's fields.
+func (u *customFieldUsecase) authorize(ctx context.Context, projectID uint) error {
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return fmt.Errorf("%w: only the project owner or an admin can manage custom fields", domain.ErrForbidden)
+	}
+	return nil
+}
+
+func validateFieldDefinition(field *domain.CustomField) error {
+	if field.Name == "" {
+		return fmt.Errorf("%w: field name is required", domain.ErrInvalidInput)
+	}
+	if !field.Type.Valid() {
+		return fmt.Errorf("%w: unknown field type %q", domain.ErrInvalidInput, field.Type)
+	}
+
+	isSelect := field.Type == domain.CustomFieldSingleSelect || field.Type == domain.CustomFieldMultiSelect
+	if isSelect && len(field.Options) == 0 {
+		return fmt.Errorf("%w: %s fields need at least one option", domain.ErrInvalidInput, field.Type)
+	}
+	if !isSelect && len(field.Options) > 0 {
+		return fmt.Errorf("%w: options are only allowed on select fields", domain.ErrInvalidInput)
+	}
+
+	seen := make(map[string]bool, len(field.Options))
+	for _, option := range field.Options {
+		if option == "" || seen[option] {
+			return fmt.Errorf("%w: options must be unique and non-empty", domain.ErrInvalidInput)
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+func (u *customFieldUsecase) Create(c context.Context, field *domain.CustomField) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := u.authorize(ctx, field.ProjectID); err != nil {
+		return err
+	}
+	if err := validateFieldDefinition(field); err != nil {
+		return err
+	}
+
+	return u.fieldRepo.Create(ctx, field)
+}
+
+func (u *customFieldUsecase) GetByProjectID(c context.Context, projectID uint) ([]domain.CustomField, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	return u.fieldRepo.GetByProjectID(ctx, projectID)
+}
+
+func (u *customFieldUsecase) Update(c context.Context, projectID, id uint, changes domain.CustomFieldChanges) (*domain.CustomField, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	existing, err := u.fieldRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.ProjectID != projectID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := u.authorize(ctx, existing.ProjectID); err != nil {
+		return nil, err
+	}
+	if changes.Type != "" && changes.Type != existing.Type {
+		return nil, fmt.Errorf("%w: a field's type cannot be changed", domain.ErrInvalidInput)
+	}
+
+	if changes.Name != "" {
+		existing.Name = changes.Name
+	}
+	if changes.Options != nil {
+		existing.Options = changes.Options
+	}
+	if changes.Required != nil {
+		existing.Required = *changes.Required
+	}
+	if changes.Position != nil {
+		existing.Position = *changes.Position
+	}
+	if err := validateFieldDefinition(existing); err != nil {
+		return nil, err
+	}
+
+	if err := u.fieldRepo.Update(ctx, existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (u *customFieldUsecase) Delete(c context.Context, projectID, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	field, err := u.fieldRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if field.ProjectID != projectID {
+		return gorm.ErrRecordNotFound
+	}
+	if err := u.authorize(ctx, projectID); err != nil {
+		return err
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.fieldRepo.DeleteValuesByField(ctx, id); err != nil {
+			return err
+		}
+		return u.fieldRepo.Delete(ctx, id)
+	})
+	if err == nil {
+		invalidateTaskCaches(ctx, u.redisClient, projectID)
+	}
+	return err
+}
+
+func (u *customFieldUsecase) SetTaskValues(c context.Context, taskID uint, values map[uint]json.RawMessage) ([]domain.CustomFieldValue, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := u.fieldRepo.GetByProjectID(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*domain.CustomField, len(fields))
+	for i := range fields {
+		byID[fields[i].ID] = &fields[i]
+	}
+
+	var upserts []domain.CustomFieldValue
+	var cleared []uint
+	now := time.Now()
+	for fieldID, raw := range values {
+		field, ok := byID[fieldID]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %d is not defined on this project", domain.ErrInvalidInput, fieldID)
+		}
+
+		if isJSONNull(raw) {
+			if field.Required {
+				return nil, fmt.Errorf("%w: field %q is required", domain.ErrInvalidInput, field.Name)
+			}
+			cleared = append(cleared, fieldID)
+			continue
+		}
+
+		normalized, err := normalizeFieldValue(ctx, u.userRepo, field, raw)
+		if err != nil {
+			return nil, err
+		}
+		upserts = append(upserts, domain.CustomFieldValue{
+			TaskID:    taskID,
+			FieldID:   fieldID,
+			Value:     domain.JSONValue(normalized),
+			UpdatedAt: now,
+		})
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.fieldRepo.DeleteValues(ctx, taskID, cleared); err != nil {
+			return err
+		}
+		return u.fieldRepo.UpsertValues(ctx, upserts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+
+	return u.fieldRepo.GetValues(ctx, taskID)
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
+
+// newTaskFieldValues validates a new task's values, keyed by field ID,
+// against the project's fields. Every required field needs a value.
+func newTaskFieldValues(ctx context.Context, userRepo domain.UserRepository, fields []domain.CustomField, values map[uint]json.RawMessage) ([]domain.CustomFieldValue, error) {
+	byID := make(map[uint]*domain.CustomField, len(fields))
+	for i := range fields {
+		byID[fields[i].ID] = &fields[i]
+	}
+	for fieldID := range values {
+		if _, ok := byID[fieldID]; !ok {
+			return nil, fmt.Errorf("%w: field %d is not defined on this project", domain.ErrInvalidInput, fieldID)
+		}
+	}
+
+	var result []domain.CustomFieldValue
+	for i := range fields {
+		field := &fields[i]
+		raw := values[field.ID]
+		if isJSONNull(raw) {
+			if field.Required {
+				return nil, fmt.Errorf("%w: field %q is required", domain.ErrInvalidInput, field.Name)
+			}
+			continue
+		}
+		normalized, err := normalizeFieldValue(ctx, userRepo, field, raw)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, domain.CustomFieldValue{FieldID: field.ID, Value: domain.JSONValue(normalized)})
+	}
+	return result, nil
+}
+
+// normalizeFieldValue checks raw against the field's type and returns the
+// canonical JSON to store.
+func normalizeFieldValue(ctx context.Context, userRepo domain.UserRepository, field *domain.CustomField, raw json.RawMessage) ([]byte, error) {
+	invalid := func(expected string) error {
+		return fmt.Errorf("%w: field %q expects %s", domain.ErrInvalidInput, field.Name, expected)
+	}
+
+	switch field.Type {
+	case domain.CustomFieldText:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, invalid("a string")
+		}
+		return json.Marshal(v)
+
+	case domain.CustomFieldNumber:
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, invalid("a number")
+		}
+		return json.Marshal(v)
+
+	case domain.CustomFieldDate:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, invalid("a date string")
+		}
+		date, err := parseFieldDate(v)
+		if err != nil {
+			return nil, invalid("a date in YYYY-MM-DD or RFC 3339 format")
+		}
+		return json.Marshal(date)
+
+	case domain.CustomFieldSingleSelect:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil || !containsString(field.Options, v) {
+			return nil, invalid(fmt.Sprintf("one of %q", []string(field.Options)))
+		}
+		return json.Marshal(v)
+
+	case domain.CustomFieldMultiSelect:
+		var v []string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, invalid("an array of options")
+		}
+		seen := make(map[string]bool, len(v))
+		choices := make([]string, 0, len(v))
+		for _, choice := range v {
+			if !containsString(field.Options, choice) {
+				return nil, invalid(fmt.Sprintf("options from %q", []string(field.Options)))
+			}
+			if !seen[choice] {
+				seen[choice] = true
+				choices = append(choices, choice)
+			}
+		}
+		return json.Marshal(choices)
+
+	case domain.CustomFieldUser:
+		var v uint
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, invalid("a user ID")
+		}
+		if _, err := userRepo.GetByID(ctx, v); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: field %q references unknown user %d", domain.ErrInvalidInput, field.Name, v)
+			}
+			return nil, err
+		}
+		return json.Marshal(v)
+	}
+
+	return nil, invalid("a supported type")
+}
+
+// parseFieldDate accepts a calendar date or an RFC 3339 timestamp and
+// returns the calendar date.
+func parseFieldDate(v string) (string, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCustomFieldFilters turns raw query values keyed by field ID into
+// typed filters for the project's fields.
+func resolveCustomFieldFilters(ctx context.Context, fieldRepo domain.CustomFieldRepository, projectID uint, raw map[uint]string) ([]domain.CustomFieldFilter, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	fields, err := fieldRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]domain.CustomField, len(fields))
+	for _, field := range fields {
+		byID[field.ID] = field
+	}
+
+	filters := make([]domain.CustomFieldFilter, 0, len(raw))
+	for fieldID, value := range raw {
+		field, ok := byID[fieldID]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %d is not defined on this project", domain.ErrInvalidInput, fieldID)
+		}
+
+		switch field.Type {
+		case domain.CustomFieldNumber:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: filter on %q expects a number", domain.ErrInvalidInput, field.Name)
+			}
+			// Match the text form json.Marshal stored
+			b, _ := json.Marshal(n)
+			value = string(b)
+		case domain.CustomFieldDate:
+			date, err := parseFieldDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: filter on %q expects a date", domain.ErrInvalidInput, field.Name)
+			}
+			value = date
+		}
+
+		filters = append(filters, domain.CustomFieldFilter{FieldID: fieldID, Type: field.Type, Value: value})
+	}
+	// Stable order keeps cache keys for the same filters identical
+	sort.Slice(filters, func(i, j int) bool { return filters[i].FieldID < filters[j].FieldID })
+	return filters, nil
+}
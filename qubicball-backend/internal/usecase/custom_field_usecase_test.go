@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"qubicball-backend/internal/domain"
+)
+
+func TestValidateFieldDefinition(t *testing.T) {
+	tests := []struct {
+		name  string
+		field domain.CustomField
+		ok    bool
+	}{
+		{"text", domain.CustomField{Name: "Notes", Type: domain.CustomFieldText}, true},
+		{"select", domain.CustomField{Name: "Size", Type: domain.CustomFieldSingleSelect, Options: domain.StringList{"S", "M"}}, true},
+		{"no name", domain.CustomField{Type: domain.CustomFieldText}, false},
+		{"unknown type", domain.CustomField{Name: "X", Type: "color"}, false},
+		{"select without options", domain.CustomField{Name: "Size", Type: domain.CustomFieldMultiSelect}, false},
+		{"options on a number", domain.CustomField{Name: "Points", Type: domain.CustomFieldNumber, Options: domain.StringList{"1"}}, false},
+		{"empty option", domain.CustomField{Name: "Size", Type: domain.CustomFieldSingleSelect, Options: domain.StringList{"S", ""}}, false},
+		{"duplicate option", domain.CustomField{Name: "Size", Type: domain.CustomFieldSingleSelect, Options: domain.StringList{"S", "S"}}, false},
+	}
+	for _, tt := range tests {
+		err := validateFieldDefinition(&tt.field)
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%s: error = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
+
+func TestNormalizeFieldValue(t *testing.T) {
+	options := domain.StringList{"low", "high"}
+	tests := []struct {
+		fieldType domain.CustomFieldType
+		raw       string
+		want      string // Empty when the value is invalid
+	}{
+		{domain.CustomFieldText, `"hello"`, `"hello"`},
+		{domain.CustomFieldText, `12`, ""},
+		{domain.CustomFieldNumber, `12.50`, `12.5`},
+		{domain.CustomFieldNumber, `"12"`, ""},
+		{domain.CustomFieldDate, `"2026-02-28"`, `"2026-02-28"`},
+		{domain.CustomFieldDate, `"2026-02-28T23:30:00+02:00"`, `"2026-02-28"`},
+		{domain.CustomFieldDate, `"2026-02-30"`, ""},
+		{domain.CustomFieldDate, `"28/02/2026"`, ""},
+		{domain.CustomFieldSingleSelect, `"high"`, `"high"`},
+		{domain.CustomFieldSingleSelect, `"medium"`, ""},
+		{domain.CustomFieldMultiSelect, `["high","low","high"]`, `["high","low"]`},
+		{domain.CustomFieldMultiSelect, `["high","medium"]`, ""},
+		{domain.CustomFieldMultiSelect, `"high"`, ""},
+		{domain.CustomFieldUser, `"alice"`, ""},
+	}
+	for _, tt := range tests {
+		field := &domain.CustomField{Name: "Field", Type: tt.fieldType, Options: options}
+		// User IDs that parse are looked up; none of these get that far
+		got, err := normalizeFieldValue(context.Background(), nil, field, json.RawMessage(tt.raw))
+		if tt.want == "" {
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("%s %s: error = %v, want ErrInvalidInput", tt.fieldType, tt.raw, err)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s %s = %s, %v, want %s", tt.fieldType, tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestNewTaskFieldValues(t *testing.T) {
+	fields := []domain.CustomField{
+		{ID: 1, Name: "Customer", Type: domain.CustomFieldText, Required: true},
+		{ID: 2, Name: "Points", Type: domain.CustomFieldNumber},
+	}
+	tests := []struct {
+		name   string
+		values map[uint]json.RawMessage
+		want   map[uint]string // Nil when the values are rejected
+	}{
+		{"required only", map[uint]json.RawMessage{1: json.RawMessage(`"ACME"`)}, map[uint]string{1: `"ACME"`}},
+		{"both", map[uint]json.RawMessage{1: json.RawMessage(`"ACME"`), 2: json.RawMessage(`3`)}, map[uint]string{1: `"ACME"`, 2: `3`}},
+		{"optional null", map[uint]json.RawMessage{1: json.RawMessage(`"ACME"`), 2: json.RawMessage(`null`)}, map[uint]string{1: `"ACME"`}},
+		{"required missing", map[uint]json.RawMessage{2: json.RawMessage(`3`)}, nil},
+		{"required null", map[uint]json.RawMessage{1: json.RawMessage(`null`)}, nil},
+		{"no values", nil, nil},
+		{"unknown field", map[uint]json.RawMessage{1: json.RawMessage(`"ACME"`), 9: json.RawMessage(`1`)}, nil},
+		{"invalid value", map[uint]json.RawMessage{1: json.RawMessage(`"ACME"`), 2: json.RawMessage(`"three"`)}, nil},
+	}
+	for _, tt := range tests {
+		got, err := newTaskFieldValues(context.Background(), nil, fields, tt.values)
+		if tt.want == nil {
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("%s: error = %v, want ErrInvalidInput", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		values := make(map[uint]string, len(got))
+		for _, value := range got {
+			values[value.FieldID] = string(value.Value)
+		}
+		if !reflect.DeepEqual(values, tt.want) {
+			t.Errorf("%s: values = %v, want %v", tt.name, values, tt.want)
+		}
+	}
+
+	// Projects without required fields take tasks without values
+	if got, err := newTaskFieldValues(context.Background(), nil, fields[1:], nil); err != nil || len(got) != 0 {
+		t.Errorf("no required fields = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	maxLabelNameLength = 50
+	defaultLabelColor  = "#6b7280"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type labelUsecase struct {
+	labelRepo      domain.LabelRepository
+	projectRepo    domain.ProjectRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewLabelUsecase(labelRepo domain.LabelRepository, projectRepo domain.ProjectRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.LabelUsecase {
+	return &labelUsecase{
+		labelRepo:      labelRepo,
+		projectRepo:    projectRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+// authorize checks that the request's actor may manage the project's labels.
+func (u *labelUsecase) authorize(ctx context.Context, projectID uint) error {
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return fmt.Errorf("%w: only the project owner or an admin can manage labels", domain.ErrForbidden)
+	}
+	return nil
+}
+
+// validateLabel normalizes the label's name and color.
+func validateLabel(label *domain.Label) error {
+	label.Name = strings.TrimSpace(label.Name)
+	if label.Name == "" {
+		return fmt.Errorf("%w: label name is required", domain.ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(label.Name) > maxLabelNameLength {
+		return fmt.Errorf("%w: label name must be at most %d characters", domain.ErrInvalidInput, maxLabelNameLength)
+	}
+	label.Color = strings.ToLower(strings.TrimSpace(label.Color))
+	if label.Color == "" {
+		label.Color = defaultLabelColor
+	}
+	if !labelColorPattern.MatchString(label.Color) {
+		return fmt.Errorf("%w: color must be a hex color like #1f6feb", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+// checkNameFree fails when another label of the project has the name.
+func (u *labelUsecase) checkNameFree(ctx context.Context, label *domain.Label) error {
+	existing, err := u.labelRepo.GetByName(ctx, label.ProjectID, label.Name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != label.ID {
+		return fmt.Errorf("%w: label %q already exists; merge into it instead", domain.ErrConflict, existing.Name)
+	}
+	return nil
+}
+
+func (u *labelUsecase) Create(c context.Context, label *domain.Label) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := u.authorize(ctx, label.ProjectID); err != nil {
+		return err
+	}
+	if err := validateLabel(label); err != nil {
+		return err
+	}
+	label.ID = 0
+	if err := u.checkNameFree(ctx, label); err != nil {
+		return err
+	}
+
+	return u.labelRepo.Create(ctx, label)
+}
+
+func (u *labelUsecase) GetByProjectID(c context.Context, projectID uint) ([]domain.Label, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return u.labelRepo.GetByProjectID(ctx, projectID)
+}
+
+func (u *labelUsecase) Update(c context.Context, label *domain.Label) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	existing, err := u.projectLabel(ctx, label.ProjectID, label.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.authorize(ctx, existing.ProjectID); err != nil {
+		return err
+	}
+
+	if label.Name != "" {
+		existing.Name = label.Name
+	}
+	if label.Color != "" {
+		existing.Color = label.Color
+	}
+	if err := validateLabel(existing); err != nil {
+		return err
+	}
+	if err := u.checkNameFree(ctx, existing); err != nil {
+		return err
+	}
+
+	if err := u.labelRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	// Cached task listings embed the label
+	invalidateTaskCaches(ctx, u.redisClient, existing.ProjectID)
+	*label = *existing
+	return nil
+}
+
+func (u *labelUsecase) Delete(c context.Context, projectID, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.projectLabel(ctx, projectID, id); err != nil {
+		return err
+	}
+	if err := u.authorize(ctx, projectID); err != nil {
+		return err
+	}
+
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.labelRepo.Delete(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, projectID)
+	return nil
+}
+
+func (u *labelUsecase) Merge(c context.Context, projectID, sourceID, targetID uint) (*domain.Label, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: a label cannot be merged into itself", domain.ErrInvalidInput)
+	}
+	if _, err := u.projectLabel(ctx, projectID, sourceID); err != nil {
+		return nil, err
+	}
+	target, err := u.projectLabel(ctx, projectID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authorize(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.labelRepo.Merge(ctx, sourceID, targetID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, projectID)
+	return target, nil
+}
+
+// projectLabel loads a label, treating one of another project as missing.
+func (u *labelUsecase) projectLabel(ctx context.Context, projectID, id uint) (*domain.Label, error) {
+	label, err := u.labelRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if label.ProjectID != projectID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return label, nil
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Comment bodies support a small markdown subset: paragraphs and line
+// breaks, fenced and inline code, **bold**, *italics*, [links](url), "-"
+// and "1." lists, and @mentions. Text is escaped before any markup is added
+// and only the tags below are ever emitted, so the output is safe to embed.
+
+var (
+	mentionPattern    = regexp.MustCompile(`(^|[^\w@])@([\w.+-]+(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+	codeSpanPattern   = regexp.MustCompile("`[^`\n]*`")
+	codeFencePattern  = regexp.MustCompile("(?s)```.*?(```|$)")
+	linkPattern       = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+	boldPattern       = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	italicPattern     = regexp.MustCompile(`\*([^*\n]+)\*`)
+	unorderedListItem = regexp.MustCompile(`^\s*[-*]\s+(.*)$`)
+	orderedListItem   = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+)
+
+// extractMentions returns the distinct @handles in body, lower-cased and in
+// order of appearance. Handles inside code are ignored.
+func extractMentions(body string) []string {
+	body = codeFencePattern.ReplaceAllString(body, "")
+	body = codeSpanPattern.ReplaceAllString(body, "")
+
+	seen := make(map[string]bool)
+	var handles []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[2], ".-"))
+		if handle != "" && !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// renderMarkdown renders a comment body as HTML. mentions maps lower-cased
+// handles to user IDs; other @handles stay plain text.
+func renderMarkdown(body string, mentions map[string]uint) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">")
+			listTag = ""
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			flushParagraph()
+			closeList()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+
+		tag, item := "", ""
+		if m := unorderedListItem.FindStringSubmatch(line); m != nil {
+			tag, item = "ul", m[1]
+		} else if m := orderedListItem.FindStringSubmatch(line); m != nil {
+			tag, item = "ol", m[1]
+		}
+		if tag != "" {
+			flushParagraph()
+			if listTag != tag {
+				closeList()
+				out.WriteString("<" + tag + ">")
+				listTag = tag
+			}
+			out.WriteString("<li>" + renderInline(item, mentions) + "</li>")
+			continue
+		}
+
+		closeList()
+		paragraph = append(paragraph, renderInline(line, mentions))
+	}
+	flushParagraph()
+	closeList()
+
+	return out.String()
+}
+
+// renderInline renders the markup within a single line.
+func renderInline(line string, mentions map[string]uint) string {
+	var out strings.Builder
+	// Odd segments sit between backticks and are rendered as code
+	segments := strings.Split(line, "`")
+	for i, segment := range segments {
+		switch {
+		case i%2 == 1 && i < len(segments)-1:
+			out.WriteString("<code>" + html.EscapeString(segment) + "</code>")
+		case i%2 == 1:
+			// Unbalanced backtick
+			out.WriteString("`" + renderText(segment, mentions))
+		default:
+			out.WriteString(renderText(segment, mentions))
+		}
+	}
+	return out.String()
+}
+
+// renderText renders links, emphasis and mentions in text outside code.
+// Emphasis and mentions apply to the text around links and to their labels,
+// never to a link's href.
+func renderText(text string, mentions map[string]uint) string {
+	escaped := html.EscapeString(text)
+
+	var out strings.Builder
+	last := 0
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(escaped, -1) {
+		out.WriteString(renderSpans(escaped[last:m[0]], mentions))
+		label, href := renderSpans(escaped[m[2]:m[3]], mentions), escaped[m[4]:m[5]]
+		lower := strings.ToLower(href)
+		if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:") {
+			fmt.Fprintf(&out, `<a href="%s" rel="nofollow noopener noreferrer" target="_blank">%s</a>`, href, label)
+		} else {
+			out.WriteString(label)
+		}
+		last = m[1]
+	}
+	out.WriteString(renderSpans(escaped[last:], mentions))
+	return out.String()
+}
+
+// renderSpans renders emphasis and mentions in escaped text.
+func renderSpans(escaped string, mentions map[string]uint) string {
+	escaped = boldPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+	escaped = italicPattern.ReplaceAllString(escaped, "<em>$1</em>")
+
+	return mentionPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+		m := mentionPattern.FindStringSubmatch(match)
+		handle := strings.TrimRight(m[2], ".-")
+		userID, ok := mentions[strings.ToLower(handle)]
+		if !ok {
+			return match
+		}
+		rest := strings.TrimPrefix(m[2], handle)
+		return fmt.Sprintf(`%s<span class="mention" data-user-id="%d">@%s</span>%s`, m[1], userID, handle, rest)
+	})
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	mentions := map[string]uint{"alice": 1}
+	const attrs = ` rel="nofollow noopener noreferrer" target="_blank"`
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"plain", "hello", "<p>hello</p>"},
+		{"escapes html", "<b>&</b>", "<p>&lt;b&gt;&amp;&lt;/b&gt;</p>"},
+		{"line break", "a\nb", "<p>a<br>b</p>"},
+		{"paragraphs", "a\n\nb", "<p>a</p><p>b</p>"},
+		{"emphasis", "**bold** and *it*", "<p><strong>bold</strong> and <em>it</em></p>"},
+		{"mention", "hi @alice.", `<p>hi <span class="mention" data-user-id="1">@alice</span>.</p>`},
+		{"unknown mention", "@bob hi", "<p>@bob hi</p>"},
+		{"code span", "`**x** @alice`", "<p><code>**x** @alice</code></p>"},
+		{"fence", "```\n<x> *y*\n```", "<pre><code>&lt;x&gt; *y*</code></pre>"},
+		{"list", "- a\n- b\n1. c", "<ul><li>a</li><li>b</li></ul><ol><li>c</li></ol>"},
+		{"link", "[x](https://h/p)", `<p><a href="https://h/p"` + attrs + `>x</a></p>`},
+		{"mention in href", "[x](https://h/@alice)", `<p><a href="https://h/@alice"` + attrs + `>x</a></p>`},
+		{"bold in href", "[x](https://h/**a**b**)", `<p><a href="https://h/**a**b**"` + attrs + `>x</a></p>`},
+		{"italic in href", "[x](https://h/*a*)", `<p><a href="https://h/*a*"` + attrs + `>x</a></p>`},
+		{"quote in href", `[x](https://h/"onclick=y)`, `<p><a href="https://h/&#34;onclick=y"` + attrs + `>x</a></p>`},
+		{
+			"markup in label",
+			"see [@alice **docs**](https://h) now",
+			`<p>see <a href="https://h"` + attrs + `><span class="mention" data-user-id="1">@alice</span> <strong>docs</strong></a> now</p>`,
+		},
+		{"link in list", "- [a](http://x/*y*)", `<ul><li><a href="http://x/*y*"` + attrs + `>a</a></li></ul>`},
+		{"unsafe scheme", "[bad](javascript:alert)", "<p>bad</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderMarkdown(tt.body, mentions); got != tt.want {
+				t.Errorf("renderMarkdown(%q)\n got %s\nwant %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"hi @Alice and @bob, @alice again", []string{"alice", "bob"}},
+		{"mail me at a@b.co", nil},
+		{"@carol.", []string{"carol"}},
+		{"`@dave` and\n```\n@erin\n```", nil},
+		{"@frank@example.com", []string{"frank@example.com"}},
+	}
+	for _, tt := range tests {
+		if got := extractMentions(tt.body); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("extractMentions(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
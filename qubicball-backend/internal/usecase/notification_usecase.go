@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+const notificationListLimit = 50
+
+type notificationUsecase struct {
+	notificationRepo domain.NotificationRepository
+	contextTimeout   time.Duration
+}
+
+func NewNotificationUsecase(notificationRepo domain.NotificationRepository, timeout time.Duration) domain.NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepo: notificationRepo,
+		contextTimeout:   timeout,
+	}
+}
+
+func notificationActor(ctx context.Context) (domain.Actor, error) {
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return actor, fmt.Errorf("%w: notifications require an authenticated user", domain.ErrForbidden)
+	}
+	return actor, nil
+}
+
+func (u *notificationUsecase) List(c context.Context, unreadOnly bool) ([]domain.Notification, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, err := notificationActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.notificationRepo.GetByUserID(ctx, actor.UserID, unreadOnly, notificationListLimit)
+}
+
+func (u *notificationUsecase) MarkRead(c context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, err := notificationActor(ctx)
+	if err != nil {
+		return err
+	}
+	return u.notificationRepo.MarkRead(ctx, actor.UserID, id)
+}
+
+func (u *notificationUsecase) MarkAllRead(c context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, err := notificationActor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return u.notificationRepo.MarkAllRead(ctx, actor.UserID)
+}
@@ -0,0 +1,552 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type projectBundleUsecase struct {
+	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	sprintRepo     domain.SprintRepository
+	fieldRepo      domain.CustomFieldRepository
+	labelRepo      domain.LabelRepository
+	userRepo       domain.UserRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewProjectBundleUsecase(projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, sprintRepo domain.SprintRepository, fieldRepo domain.CustomFieldRepository, labelRepo domain.LabelRepository, userRepo domain.UserRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.ProjectBundleUsecase {
+	return &projectBundleUsecase{
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		sprintRepo:     sprintRepo,
+		fieldRepo:      fieldRepo,
+		labelRepo:      labelRepo,
+		userRepo:       userRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *projectBundleUsecase) Export(c context.Context, projectID uint) (*domain.ProjectBundle, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	// Tasks are exported in their manual order, which import restores
+	page, err := u.taskRepo.GetByProjectID(ctx, projectID, domain.TaskQuery{Sort: domain.TaskSort{Field: domain.TaskSortRank}})
+	if err != nil {
+		return nil, err
+	}
+	tasks := page.Tasks
+	sprints, err := u.sprintRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := u.fieldRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := u.labelRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &domain.ProjectBundle{
+		FormatVersion: domain.ProjectBundleFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Metadata: domain.BundleMetadata{
+			SourceProjectID: project.ID,
+			TaskCount:       len(tasks),
+		},
+		Project: domain.BundleProject{
+			Name:        project.Name,
+			Description: project.Description,
+			OwnerEmail:  project.Owner.Email,
+			IsTemplate:  project.IsTemplate,
+			Settings:    project.Settings,
+			Workflow:    project.Workflow,
+			WIPLimits:   project.WIPLimits,
+		},
+		CustomFields: make([]domain.BundleCustomField, 0, len(fields)),
+		Labels:       make([]domain.BundleLabel, 0, len(labels)),
+		Sprints:      make([]domain.BundleSprint, 0, len(sprints)),
+		Tasks:        make([]domain.BundleTask, 0, len(tasks)),
+	}
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		if exporter, err := u.userRepo.GetByID(ctx, actor.UserID); err == nil {
+			bundle.Metadata.ExportedBy = exporter.Email
+		}
+	}
+
+	// Source IDs double as bundle refs
+	fieldTypes := make(map[uint]domain.CustomFieldType, len(fields))
+	for _, field := range fields {
+		fieldTypes[field.ID] = field.Type
+		bundle.CustomFields = append(bundle.CustomFields, domain.BundleCustomField{
+			Ref:      field.ID,
+			Name:     field.Name,
+			Type:     field.Type,
+			Options:  field.Options,
+			Required: field.Required,
+			Position: field.Position,
+		})
+	}
+	for _, label := range labels {
+		bundle.Labels = append(bundle.Labels, domain.BundleLabel{Name: label.Name, Color: label.Color})
+	}
+	for _, sprint := range sprints {
+		bundle.Sprints = append(bundle.Sprints, domain.BundleSprint{
+			Ref:       sprint.ID,
+			Name:      sprint.Name,
+			Goal:      sprint.Goal,
+			StartDate: sprint.StartDate,
+			EndDate:   sprint.EndDate,
+			Status:    sprint.Status,
+		})
+	}
+
+	userEmails, err := u.userFieldEmails(ctx, tasks, fieldTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		bt := domain.BundleTask{
+			Title:           task.Title,
+			Description:     task.Description,
+			Status:          task.Status,
+			Priority:        task.Priority,
+			DueDate:         task.DueDate,
+			StartDate:       task.StartDate,
+			DurationDays:    task.DurationDays,
+			EstimateMinutes: task.EstimateMinutes,
+			SprintRef:       task.SprintID,
+		}
+		if task.Assignee != nil {
+			bt.AssigneeEmail = task.Assignee.Email
+		}
+		for _, user := range task.Assignees {
+			if task.AssigneeID == nil || user.ID != *task.AssigneeID {
+				bt.AssigneeEmails = append(bt.AssigneeEmails, user.Email)
+			}
+		}
+		for _, label := range task.Labels {
+			bt.Labels = append(bt.Labels, label.Name)
+		}
+		for _, value := range task.CustomFields {
+			v := value.Value
+			if fieldTypes[value.FieldID] == domain.CustomFieldUser {
+				var userID uint
+				_ = json.Unmarshal(value.Value, &userID)
+				email, _ := json.Marshal(userEmails[userID])
+				v = domain.JSONValue(email)
+			}
+			bt.CustomFields = append(bt.CustomFields, domain.BundleFieldValue{FieldRef: value.FieldID, Value: v})
+		}
+		bundle.Tasks = append(bundle.Tasks, bt)
+	}
+
+	return bundle, nil
+}
+
+// userFieldEmails resolves the users referenced by user-type custom field
+// values to their emails.
+func (u *projectBundleUsecase) userFieldEmails(ctx context.Context, tasks []domain.Task, fieldTypes map[uint]domain.CustomFieldType) (map[uint]string, error) {
+	var ids []uint
+	for _, task := range tasks {
+		for _, value := range task.CustomFields {
+			if fieldTypes[value.FieldID] != domain.CustomFieldUser {
+				continue
+			}
+			var userID uint
+			if err := json.Unmarshal(value.Value, &userID); err == nil {
+				ids = append(ids, userID)
+			}
+		}
+	}
+
+	users, err := u.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	emails := make(map[uint]string, len(users))
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+	return emails, nil
+}
+
+func validateBundle(bundle *domain.ProjectBundle) error {
+	if bundle.FormatVersion < 1 || bundle.FormatVersion > domain.ProjectBundleFormatVersion {
+		return fmt.Errorf("%w: unsupported bundle format version %d (this server reads up to %d)",
+			domain.ErrInvalidInput, bundle.FormatVersion, domain.ProjectBundleFormatVersion)
+	}
+	if strings.TrimSpace(bundle.Project.Name) == "" {
+		return fmt.Errorf("%w: bundle project has no name", domain.ErrInvalidInput)
+	}
+
+	fieldRefs := make(map[uint]bool, len(bundle.CustomFields))
+	for i := range bundle.CustomFields {
+		bf := bundle.CustomFields[i]
+		if fieldRefs[bf.Ref] {
+			return fmt.Errorf("%w: duplicate custom field ref %d", domain.ErrInvalidInput, bf.Ref)
+		}
+		fieldRefs[bf.Ref] = true
+		field := domain.CustomField{Name: bf.Name, Type: bf.Type, Options: bf.Options}
+		if err := validateFieldDefinition(&field); err != nil {
+			return fmt.Errorf("custom field %q: %w", bf.Name, err)
+		}
+	}
+
+	labelNames := make(map[string]bool, len(bundle.Labels))
+	for i := range bundle.Labels {
+		label := domain.Label{Name: bundle.Labels[i].Name, Color: bundle.Labels[i].Color}
+		if err := validateLabel(&label); err != nil {
+			return fmt.Errorf("label %q: %w", bundle.Labels[i].Name, err)
+		}
+		if labelNames[strings.ToLower(label.Name)] {
+			return fmt.Errorf("%w: duplicate label %q", domain.ErrInvalidInput, label.Name)
+		}
+		labelNames[strings.ToLower(label.Name)] = true
+	}
+
+	sprintRefs := make(map[uint]bool, len(bundle.Sprints))
+	activeSprint := ""
+	for i := range bundle.Sprints {
+		bs := bundle.Sprints[i]
+		if sprintRefs[bs.Ref] {
+			return fmt.Errorf("%w: duplicate sprint ref %d", domain.ErrInvalidInput, bs.Ref)
+		}
+		sprintRefs[bs.Ref] = true
+		if bs.Status != "" && !bs.Status.Valid() {
+			return fmt.Errorf("%w: sprint %q has unknown status %q", domain.ErrInvalidInput, bs.Name, bs.Status)
+		}
+		if bs.Status == domain.SprintStatusActive {
+			// A project runs one sprint at a time
+			if activeSprint != "" {
+				return fmt.Errorf("%w: sprints %q and %q are both active", domain.ErrInvalidInput, activeSprint, bs.Name)
+			}
+			activeSprint = bs.Name
+		}
+		sprint := domain.Sprint{Name: bs.Name, StartDate: bs.StartDate, EndDate: bs.EndDate}
+		if err := validateSprintDates(&sprint); err != nil {
+			return fmt.Errorf("sprint %q: %w", bs.Name, err)
+		}
+	}
+
+	if bundle.Project.Workflow != nil {
+		if err := bundle.Project.Workflow.Validate(); err != nil {
+			return fmt.Errorf("workflow: %w", err)
+		}
+	}
+	workflow := bundle.Project.Workflow
+	if workflow == nil {
+		workflow = domain.DefaultWorkflow()
+	}
+	if err := bundle.Project.WIPLimits.Validate(workflow); err != nil {
+		return fmt.Errorf("wip_limits: %w", err)
+	}
+
+	for i, task := range bundle.Tasks {
+		if strings.TrimSpace(task.Title) == "" {
+			return fmt.Errorf("%w: task %d has no title", domain.ErrInvalidInput, i+1)
+		}
+	}
+	return nil
+}
+
+func (u *projectBundleUsecase) Import(c context.Context, bundle *domain.ProjectBundle, dryRun bool) (*domain.ProjectImportReport, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrForbidden
+	}
+	if err := validateBundle(bundle); err != nil {
+		return nil, err
+	}
+
+	report := &domain.ProjectImportReport{
+		DryRun:       dryRun,
+		Tasks:        len(bundle.Tasks),
+		Sprints:      len(bundle.Sprints),
+		CustomFields: len(bundle.CustomFields),
+		Labels:       len(bundle.Labels),
+		Unresolved:   []domain.UnresolvedReference{},
+	}
+	unresolved := func(kind, value, location, action string) {
+		report.Unresolved = append(report.Unresolved, domain.UnresolvedReference{
+			Kind: kind, Value: value, Location: location, Action: action,
+		})
+	}
+
+	usersByEmail, err := u.resolveBundleUsers(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &domain.Project{
+		Name:        bundle.Project.Name,
+		Description: bundle.Project.Description,
+		OwnerID:     actor.UserID,
+		IsTemplate:  bundle.Project.IsTemplate,
+		Settings:    bundle.Project.Settings,
+		Workflow:    bundle.Project.Workflow,
+		WIPLimits:   bundle.Project.WIPLimits,
+	}
+	workflow := project.EffectiveWorkflow()
+	if owner, ok := usersByEmail[strings.ToLower(bundle.Project.OwnerEmail)]; ok && owner.Active {
+		project.OwnerID = owner.ID
+	} else if bundle.Project.OwnerEmail != "" {
+		unresolved("user", bundle.Project.OwnerEmail, "project owner", "owned by the importing user")
+	}
+
+	fields := make(map[uint]*domain.CustomField, len(bundle.CustomFields))
+	for _, bf := range bundle.CustomFields {
+		fields[bf.Ref] = &domain.CustomField{Name: bf.Name, Type: bf.Type, Options: bf.Options}
+	}
+	sprintRefs := make(map[uint]bool, len(bundle.Sprints))
+	for _, bs := range bundle.Sprints {
+		sprintRefs[bs.Ref] = true
+	}
+	labelNames := make(map[string]bool, len(bundle.Labels))
+	for _, bl := range bundle.Labels {
+		labelNames[strings.ToLower(strings.TrimSpace(bl.Name))] = true
+	}
+
+	// Resolve task references up front so dry runs report everything
+	tasks := make([]domain.Task, len(bundle.Tasks))
+	taskSprintRefs := make([]*uint, len(bundle.Tasks))
+	taskFieldRefs := make([][]uint, len(bundle.Tasks))
+	taskLabelNames := make([][]string, len(bundle.Tasks))
+	ranks := spreadRanks(len(bundle.Tasks))
+	for i, bt := range bundle.Tasks {
+		location := fmt.Sprintf("task %q", bt.Title)
+		tasks[i] = domain.Task{
+			Title:        bt.Title,
+			Description:  bt.Description,
+			Status:       bt.Status,
+			Priority:     bt.Priority,
+			Rank:         ranks[i],
+			DueDate:      bt.DueDate,
+			StartDate:    bt.StartDate,
+			DurationDays: bt.DurationDays,
+		}
+		if bt.EstimateMinutes != nil {
+			if *bt.EstimateMinutes < 0 {
+				unresolved("estimate_minutes", fmt.Sprint(*bt.EstimateMinutes), location, "dropped")
+			} else {
+				tasks[i].EstimateMinutes = bt.EstimateMinutes
+				tasks[i].RemainingMinutes = bt.EstimateMinutes
+			}
+		}
+		if tasks[i].Priority == "" {
+			tasks[i].Priority = domain.TaskPriorityMedium
+		} else if !tasks[i].Priority.Valid() {
+			unresolved("priority", string(bt.Priority), location, fmt.Sprintf("set to %q", domain.TaskPriorityMedium))
+			tasks[i].Priority = domain.TaskPriorityMedium
+		}
+		if tasks[i].Status == "" {
+			tasks[i].Status = workflow.Initial
+		} else if !workflow.Has(tasks[i].Status) {
+			unresolved("status", string(bt.Status), location, fmt.Sprintf("set to %q", workflow.Initial))
+			tasks[i].Status = workflow.Initial
+		}
+
+		if bt.AssigneeEmail != "" {
+			if user, ok := usersByEmail[strings.ToLower(bt.AssigneeEmail)]; ok {
+				tasks[i].AssigneeID = &user.ID
+			} else {
+				unresolved("user", bt.AssigneeEmail, location+" assignee", "left unassigned")
+			}
+		}
+		for _, email := range bt.AssigneeEmails {
+			if user, ok := usersByEmail[strings.ToLower(email)]; ok {
+				tasks[i].AssigneeIDs = append(tasks[i].AssigneeIDs, user.ID)
+			} else {
+				unresolved("user", email, location+" assignee", "left off the task")
+			}
+		}
+		if tasks[i].AssigneeID == nil && len(tasks[i].AssigneeIDs) > 0 {
+			tasks[i].AssigneeID = &tasks[i].AssigneeIDs[0]
+		}
+
+		if bt.SprintRef != nil {
+			if sprintRefs[*bt.SprintRef] {
+				taskSprintRefs[i] = bt.SprintRef
+			} else {
+				unresolved("sprint", fmt.Sprint(*bt.SprintRef), location, "placed in the backlog")
+			}
+		}
+
+		for _, name := range bt.Labels {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if !labelNames[key] {
+				unresolved("label", name, location, "left off the task")
+				continue
+			}
+			taskLabelNames[i] = append(taskLabelNames[i], key)
+		}
+
+		for _, bv := range bt.CustomFields {
+			field, ok := fields[bv.FieldRef]
+			if !ok {
+				unresolved("custom_field", fmt.Sprint(bv.FieldRef), location, "value dropped")
+				continue
+			}
+			raw := json.RawMessage(bv.Value)
+			if field.Type == domain.CustomFieldUser {
+				var email string
+				_ = json.Unmarshal(bv.Value, &email)
+				user, ok := usersByEmail[strings.ToLower(email)]
+				if !ok {
+					unresolved("user", email, location+" custom field", "value dropped")
+					continue
+				}
+				raw, _ = json.Marshal(user.ID)
+			}
+			// Values get the same checks as when set on a task
+			value, err := normalizeFieldValue(ctx, u.userRepo, field, raw)
+			if errors.Is(err, domain.ErrInvalidInput) {
+				unresolved("custom_field_value", string(bv.Value), fmt.Sprintf("%s custom field %q", location, field.Name), "value dropped")
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			tasks[i].CustomFields = append(tasks[i].CustomFields, domain.CustomFieldValue{Value: domain.JSONValue(value)})
+			taskFieldRefs[i] = append(taskFieldRefs[i], bv.FieldRef)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.projectRepo.Create(ctx, project); err != nil {
+			return err
+		}
+
+		fieldIDs := make(map[uint]uint, len(bundle.CustomFields))
+		for _, bf := range bundle.CustomFields {
+			field := domain.CustomField{
+				ProjectID: project.ID,
+				Name:      bf.Name,
+				Type:      bf.Type,
+				Options:   bf.Options,
+				Required:  bf.Required,
+				Position:  bf.Position,
+			}
+			if err := u.fieldRepo.Create(ctx, &field); err != nil {
+				return err
+			}
+			fieldIDs[bf.Ref] = field.ID
+		}
+
+		labelIDs := make(map[string]uint, len(bundle.Labels))
+		for _, bl := range bundle.Labels {
+			label := domain.Label{ProjectID: project.ID, Name: bl.Name, Color: bl.Color}
+			_ = validateLabel(&label) // Normalizes; checked by validateBundle
+			if err := u.labelRepo.Create(ctx, &label); err != nil {
+				return err
+			}
+			labelIDs[strings.ToLower(label.Name)] = label.ID
+		}
+
+		sprintIDs := make(map[uint]uint, len(bundle.Sprints))
+		for _, bs := range bundle.Sprints {
+			sprint := domain.Sprint{
+				ProjectID: project.ID,
+				Name:      bs.Name,
+				Goal:      bs.Goal,
+				StartDate: bs.StartDate,
+				EndDate:   bs.EndDate,
+				Status:    bs.Status,
+			}
+			if sprint.Status == "" {
+				sprint.Status = domain.SprintStatusPlanned
+			}
+			if err := u.sprintRepo.Create(ctx, &sprint); err != nil {
+				return err
+			}
+			sprintIDs[bs.Ref] = sprint.ID
+		}
+
+		for i := range tasks {
+			tasks[i].ProjectID = project.ID
+			if ref := taskSprintRefs[i]; ref != nil {
+				sprintID := sprintIDs[*ref]
+				tasks[i].SprintID = &sprintID
+			}
+			for j := range tasks[i].CustomFields {
+				tasks[i].CustomFields[j].FieldID = fieldIDs[taskFieldRefs[i][j]]
+			}
+			for _, name := range taskLabelNames[i] {
+				if labelID := labelIDs[name]; !containsID(tasks[i].LabelIDs, labelID) {
+					tasks[i].LabelIDs = append(tasks[i].LabelIDs, labelID)
+				}
+			}
+		}
+		return u.taskRepo.CreateBatch(ctx, tasks)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bumpProjectListGeneration(ctx, u.redisClient)
+	report.Project = project
+	return report, nil
+}
+
+// resolveBundleUsers looks up every email the bundle references, keyed by
+// lower-cased email.
+func (u *projectBundleUsecase) resolveBundleUsers(ctx context.Context, bundle *domain.ProjectBundle) (map[string]domain.User, error) {
+	fieldTypes := make(map[uint]domain.CustomFieldType, len(bundle.CustomFields))
+	for _, bf := range bundle.CustomFields {
+		fieldTypes[bf.Ref] = bf.Type
+	}
+
+	emails := []string{bundle.Project.OwnerEmail}
+	for _, bt := range bundle.Tasks {
+		if bt.AssigneeEmail != "" {
+			emails = append(emails, bt.AssigneeEmail)
+		}
+		emails = append(emails, bt.AssigneeEmails...)
+		for _, bv := range bt.CustomFields {
+			if fieldTypes[bv.FieldRef] != domain.CustomFieldUser {
+				continue
+			}
+			var email string
+			if err := json.Unmarshal(bv.Value, &email); err == nil && email != "" {
+				emails = append(emails, email)
+			}
+		}
+	}
+
+	users, err := u.userRepo.GetByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]domain.User, len(users))
+	for _, user := range users {
+		byEmail[strings.ToLower(user.Email)] = user
+	}
+	return byEmail, nil
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+func TestValidateBundleSprints(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	sprint := func(ref uint, status domain.SprintStatus) domain.BundleSprint {
+		return domain.BundleSprint{
+			Ref:       ref,
+			Name:      "Sprint " + string(rune('0'+ref)),
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, 14),
+			Status:    status,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		sprints []domain.BundleSprint
+		wantErr bool
+	}{
+		{"no sprints", nil, false},
+		{"missing status", []domain.BundleSprint{sprint(1, "")}, false},
+		{"one of each status", []domain.BundleSprint{
+			sprint(1, domain.SprintStatusCompleted),
+			sprint(2, domain.SprintStatusActive),
+			sprint(3, domain.SprintStatusPlanned),
+		}, false},
+		{"unknown status", []domain.BundleSprint{sprint(1, "archived")}, true},
+		{"two active sprints", []domain.BundleSprint{
+			sprint(1, domain.SprintStatusActive),
+			sprint(2, domain.SprintStatusActive),
+		}, true},
+		{"duplicate ref", []domain.BundleSprint{sprint(1, ""), sprint(1, "")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle := &domain.ProjectBundle{
+				FormatVersion: domain.ProjectBundleFormatVersion,
+				Project:       domain.BundleProject{Name: "Imported"},
+				Sprints:       tt.sprints,
+			}
+			err := validateBundle(bundle)
+			if tt.wantErr && !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("validateBundle error = %v, want ErrInvalidInput", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("validateBundle error = %v", err)
+			}
+		})
+	}
+}
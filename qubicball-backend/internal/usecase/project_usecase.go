@@ -3,23 +3,43 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"qubicball-backend/internal/domain"
 
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 type projectUsecase struct {
 	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	fieldRepo      domain.CustomFieldRepository
+	labelRepo      domain.LabelRepository
+	userRepo       domain.UserRepository
+	auditRepo      domain.AuditLogRepository
+	transactor     domain.Transactor
 	redisClient    *redis.Client
 	contextTimeout time.Duration
+
+	listCacheHits   atomic.Uint64
+	listCacheMisses atomic.Uint64
 }
 
-func NewProjectUsecase(projectRepo domain.ProjectRepository, redisClient *redis.Client, timeout time.Duration) domain.ProjectUsecase {
+func NewProjectUsecase(projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, fieldRepo domain.CustomFieldRepository, labelRepo domain.LabelRepository, userRepo domain.UserRepository, auditRepo domain.AuditLogRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.ProjectUsecase {
 	return &projectUsecase{
 		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		fieldRepo:      fieldRepo,
+		labelRepo:      labelRepo,
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		transactor:     transactor,
 		redisClient:    redisClient,
 		contextTimeout: timeout,
 	}
@@ -31,7 +51,7 @@ func (u *projectUsecase) Create(c context.Context, project *domain.Project) erro
 
 	err := u.projectRepo.Create(ctx, project)
 	if err == nil {
-		u.redisClient.Del(ctx, "projects")
+		bumpProjectListGeneration(ctx, u.redisClient)
 	}
 	return err
 }
@@ -60,16 +80,67 @@ func (u *projectUsecase) GetByID(c context.Context, id uint) (*domain.Project, e
 	return project, nil
 }
 
-func (u *projectUsecase) GetAll(c context.Context, page, pageSize int) ([]domain.Project, error) {
+func (u *projectUsecase) GetAll(c context.Context, filter domain.ProjectFilter, page, pageSize int) ([]domain.Project, error) {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	_ = fmt.Sprintf("projects:%d:%d", page, pageSize)
-	// Simple caching strategy for list might be tricky with pagination, ignoring for simplicity or implementing basic invalidation
-	// For now, let's cache the whole list if needed, but given requirement "Cache project & task lists", let's attempt.
+	// Keys embed the list generation, so bumping it on writes orphans every
+	// cached page at once; orphans expire through their TTL. The user's
+	// favorites generation does the same for their personalised ordering.
+	var generation, favoritesGeneration int64
+	generations, err := u.redisClient.MGet(ctx, projectListGenerationKey, projectFavoritesGenerationKey(filter.UserID)).Result()
+	if err == nil {
+		generation = parseGeneration(generations[0])
+		favoritesGeneration = parseGeneration(generations[1])
+	}
+	cacheKey := fmt.Sprintf("projects:v%d.%d:%s:%d:%d", generation, favoritesGeneration, filter.CacheKey(), page, pageSize)
+	cachedProjects, err := u.redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var projects []domain.Project
+		if err := json.Unmarshal([]byte(cachedProjects), &projects); err == nil {
+			u.listCacheHits.Add(1)
+			return projects, nil
+		}
+	}
+	u.listCacheMisses.Add(1)
 
 	offset := (page - 1) * pageSize
-	return u.projectRepo.GetAll(ctx, pageSize, offset)
+	projects, err := u.projectRepo.GetAll(ctx, filter, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonProjects, _ := json.Marshal(projects)
+	u.redisClient.Set(ctx, cacheKey, jsonProjects, time.Minute*10)
+
+	return projects, nil
+}
+
+func (u *projectUsecase) UpdateSettings(c context.Context, id uint, settings domain.ProjectSettings) (*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return nil, fmt.Errorf("%w: only the project owner or an admin can change project settings", domain.ErrForbidden)
+	}
+
+	if err := u.projectRepo.UpdateSettings(ctx, id, settings); err != nil {
+		return nil, err
+	}
+	u.redisClient.Del(ctx, fmt.Sprintf("project:%d", id))
+	bumpProjectListGeneration(ctx, u.redisClient)
+
+	project.Settings = settings
+	return project, nil
+}
+
+func (u *projectUsecase) ListCacheMetrics() domain.CacheMetrics {
+	return newCacheMetrics(u.listCacheHits.Load(), u.listCacheMisses.Load())
 }
 
 func (u *projectUsecase) Update(c context.Context, project *domain.Project) error {
@@ -79,11 +150,7 @@ func (u *projectUsecase) Update(c context.Context, project *domain.Project) erro
 	err := u.projectRepo.Update(ctx, project)
 	if err == nil {
 		u.redisClient.Del(ctx, fmt.Sprintf("project:%d", project.ID))
-		u.redisClient.Del(ctx, "projects") // Matches the simple cache key in existing code? Wait, existing Create deleted "projects". GetAll doesn't seem to use "projects" key but "projects:page:size"?
-		// Looking at GetAll in original file: _ = fmt.Sprintf("projects:%d:%d", page, pageSize) line 67 was useless assignment.
-		// It comments "ignoring for simplicity".
-		// I should probably clear everything if possible or just accept that list might be stale.
-		// The original code `u.redisClient.Del(ctx, "projects")` suggests intent to clear list cache.
+		bumpProjectListGeneration(ctx, u.redisClient)
 	}
 	return err
 }
@@ -94,8 +161,406 @@ func (u *projectUsecase) Delete(c context.Context, id uint) error {
 
 	err := u.projectRepo.Delete(ctx, id)
 	if err == nil {
-		u.redisClient.Del(ctx, fmt.Sprintf("project:%d", id))
-		u.redisClient.Del(ctx, "projects")
+		u.redisClient.Del(ctx, fmt.Sprintf("project:%d", id), projectStatsKey(id))
+		bumpProjectListGeneration(ctx, u.redisClient)
+	}
+	return err
+}
+
+func (u *projectUsecase) Clone(c context.Context, sourceID uint, opts domain.ProjectCloneOptions) (*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	source, err := u.projectRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &domain.Project{
+		Name:        opts.Name,
+		Description: source.Description,
+		OwnerID:     opts.OwnerID,
+		IsTemplate:  opts.AsTemplate,
+		Settings:    source.Settings,
+		Workflow:    source.Workflow,
+		WIPLimits:   source.WIPLimits,
+	}
+	if clone.Name == "" {
+		clone.Name = source.Name + " (Copy)"
+	}
+	if opts.Description != nil {
+		clone.Description = *opts.Description
+	}
+	if err := u.validateAssigneeMap(ctx, opts.AssigneeMap); err != nil {
+		return nil, err
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		page, err := u.taskRepo.GetByProjectID(ctx, source.ID, domain.TaskQuery{})
+		if err != nil {
+			return err
+		}
+		tasks := page.Tasks
+		fields, err := u.fieldRepo.GetByProjectID(ctx, source.ID)
+		if err != nil {
+			return err
+		}
+		labels, err := u.labelRepo.GetByProjectID(ctx, source.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.projectRepo.Create(ctx, clone); err != nil {
+			return err
+		}
+
+		fieldIDs := make(map[uint]uint, len(fields))
+		for _, field := range fields {
+			sourceID := field.ID
+			field.ID = 0
+			field.ProjectID = clone.ID
+			if err := u.fieldRepo.Create(ctx, &field); err != nil {
+				return err
+			}
+			fieldIDs[sourceID] = field.ID
+		}
+		labelIDs := make(map[uint]uint, len(labels))
+		for _, label := range labels {
+			cloned := domain.Label{ProjectID: clone.ID, Name: label.Name, Color: label.Color}
+			if err := u.labelRepo.Create(ctx, &cloned); err != nil {
+				return err
+			}
+			labelIDs[label.ID] = cloned.ID
+		}
+
+		clones := cloneTasks(tasks, clone.ID, clone.EffectiveWorkflow().Initial, fieldIDs, labelIDs, opts)
+		if err := u.taskRepo.CreateBatch(ctx, clones); err != nil {
+			return err
+		}
+
+		// Rebuild the hierarchy now that the clones have IDs
+		cloneIDs := make(map[uint]uint, len(tasks))
+		for i := range tasks {
+			cloneIDs[tasks[i].ID] = clones[i].ID
+		}
+		for i := range tasks {
+			if tasks[i].ParentID == nil {
+				continue
+			}
+			// A parent outside the cloned set leaves the copy top-level
+			parentID, ok := cloneIDs[*tasks[i].ParentID]
+			if !ok {
+				continue
+			}
+			if err := u.taskRepo.SetParent(ctx, clones[i].ID, &parentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bumpProjectListGeneration(ctx, u.redisClient)
+	return clone, nil
+}
+
+// validateAssigneeMap checks that every non-zero target in assigneeMap is
+// an active user.
+func (u *projectUsecase) validateAssigneeMap(ctx context.Context, assigneeMap map[uint]uint) error {
+	targets := make([]uint, 0, len(assigneeMap))
+	for _, target := range assigneeMap {
+		if target != 0 {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	users, err := u.userRepo.GetByIDs(ctx, targets)
+	if err != nil {
+		return err
+	}
+	active := make(map[uint]bool, len(users))
+	for _, user := range users {
+		active[user.ID] = user.Active
+	}
+	for _, id := range targets {
+		if !active[id] {
+			return fmt.Errorf("%w: assignee_map target user %d not found or deactivated", domain.ErrInvalidInput, id)
+		}
+	}
+	return nil
+}
+
+// cloneTasks copies tasks into projectID, resetting them to status and
+// applying the due date shift and assignee remapping from opts. Custom field
+// values and labels are carried over using fieldIDs and labelIDs, which map
+// source to cloned fields and labels.
+func cloneTasks(tasks []domain.Task, projectID uint, status domain.TaskStatus, fieldIDs, labelIDs map[uint]uint, opts domain.ProjectCloneOptions) []domain.Task {
+	var shift time.Duration
+	if opts.StartDate != nil {
+		var anchor time.Time
+		for _, task := range tasks {
+			if !task.DueDate.IsZero() && (anchor.IsZero() || task.DueDate.Before(anchor)) {
+				anchor = task.DueDate
+			}
+		}
+		if !anchor.IsZero() {
+			shift = opts.StartDate.Sub(anchor)
+		}
+	}
+
+	clones := make([]domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		clone := domain.Task{
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      status,
+			Priority:    task.Priority,
+			Rank:        task.Rank,
+			ProjectID:   projectID,
+			AssigneeID:  task.AssigneeID,
+		}
+		if !task.DueDate.IsZero() {
+			clone.DueDate = task.DueDate.Add(shift)
+		}
+		if task.StartDate != nil {
+			start := task.StartDate.Add(shift)
+			clone.StartDate = &start
+		}
+		clone.DurationDays = task.DurationDays
+		// Copies start over with the full estimate and no logged time
+		clone.EstimateMinutes = task.EstimateMinutes
+		clone.RemainingMinutes = task.EstimateMinutes
+		for _, value := range task.CustomFields {
+			clone.CustomFields = append(clone.CustomFields, domain.CustomFieldValue{
+				FieldID: fieldIDs[value.FieldID],
+				Value:   value.Value,
+			})
+		}
+		clone.LabelIDs = make([]uint, 0, len(task.Labels))
+		for _, label := range task.Labels {
+			clone.LabelIDs = append(clone.LabelIDs, labelIDs[label.ID])
+		}
+		// Every assignee goes through the map; the primary one stays first
+		clone.AssigneeID = nil
+		clone.AssigneeIDs = []uint{}
+		for _, userID := range task.AssigneeSet() {
+			if target, ok := opts.AssigneeMap[userID]; ok {
+				if target == 0 {
+					continue
+				}
+				userID = target
+			}
+			clone.AssigneeIDs = append(clone.AssigneeIDs, userID)
+		}
+		if len(clone.AssigneeIDs) > 0 {
+			clone.AssigneeID = &clone.AssigneeIDs[0]
+		}
+		clones = append(clones, clone)
+	}
+	return clones
+}
+
+func (u *projectUsecase) GetStats(c context.Context, id uint, dueSoonDays int) (*domain.ProjectStats, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	// Members only see their own tasks among those due soon, so their stats
+	// are cached apart from everyone else's
+	cacheKey := projectStatsKey(id)
+	field := strconv.Itoa(dueSoonDays)
+	var dueSoonAssigneeID *uint
+	if actor, ok := domain.ActorFromContext(ctx); ok && actor.Role == domain.RoleMember {
+		dueSoonAssigneeID = &actor.UserID
+		field += ":" + strconv.FormatUint(uint64(actor.UserID), 10)
+	}
+	cachedStats, err := u.redisClient.HGet(ctx, cacheKey, field).Result()
+	if err == nil {
+		var stats domain.ProjectStats
+		if err := json.Unmarshal([]byte(cachedStats), &stats); err == nil {
+			return &stats, nil
+		}
+	}
+
+	// Ensure the project exists so unknown IDs surface as not found
+	if _, err := u.projectRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats, err := u.taskRepo.GetProjectStats(ctx, id, now, now.AddDate(0, 0, dueSoonDays), dueSoonAssigneeID)
+	if err != nil {
+		return nil, err
+	}
+	stats.DueSoonDays = dueSoonDays
+	stats.GeneratedAt = now
+	stats.CompletionPercent = percent(stats.ByStatus[domain.TaskStatusCompleted], stats.Total)
+
+	jsonStats, _ := json.Marshal(stats)
+	u.redisClient.HSet(ctx, cacheKey, field, jsonStats)
+	u.redisClient.Expire(ctx, cacheKey, time.Minute*5)
+
+	return stats, nil
+}
+
+func (u *projectUsecase) TransferOwnership(c context.Context, id uint, newOwnerID uint, version int) (*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return nil, fmt.Errorf("%w: only the project owner or an admin can transfer ownership", domain.ErrForbidden)
+	}
+	if newOwnerID == project.OwnerID {
+		return nil, fmt.Errorf("%w: user %d already owns this project", domain.ErrInvalidInput, newOwnerID)
+	}
+
+	newOwner, err := u.userRepo.GetByID(ctx, newOwnerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user %d does not exist", domain.ErrInvalidInput, newOwnerID)
+		}
+		return nil, err
+	}
+	if !newOwner.Active {
+		return nil, fmt.Errorf("%w: user %d is not active", domain.ErrInvalidInput, newOwnerID)
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"from_owner_id": project.OwnerID,
+		"to_owner_id":   newOwnerID,
+		"version":       version,
+	})
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.projectRepo.UpdateOwner(ctx, id, version, newOwnerID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: project was modified by another user, refresh and try again", domain.ErrConflict)
+			}
+			return err
+		}
+		return u.auditRepo.Create(ctx, &domain.AuditLog{
+			ActorID:    &actor.UserID,
+			Action:     domain.AuditActionProjectOwnerTransferred,
+			EntityType: "project",
+			EntityID:   id,
+			Details:    domain.JSONValue(details),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.redisClient.Del(ctx, fmt.Sprintf("project:%d", id))
+	bumpProjectListGeneration(ctx, u.redisClient)
+	log.Printf("Project %d ownership transferred from user %d to user %d by user %d", id, project.OwnerID, newOwnerID, actor.UserID)
+
+	project.OwnerID = newOwnerID
+	project.Owner = *newOwner
+	project.Version = version + 1
+	return project, nil
+}
+
+func (u *projectUsecase) SetFavorite(c context.Context, projectID uint, pinned bool) (*domain.ProjectFavorite, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrForbidden
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	favorite, err := u.projectRepo.GetFavorite(ctx, actor.UserID, projectID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		// New favorites go to the end of the user's list
+		favorites, err := u.projectRepo.GetFavorites(ctx, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		favorite = &domain.ProjectFavorite{UserID: actor.UserID, ProjectID: projectID}
+		if n := len(favorites); n > 0 {
+			favorite.Position = favorites[n-1].Position + 1
+		}
+	}
+	favorite.Pinned = pinned
+
+	if err := u.projectRepo.SaveFavorite(ctx, favorite); err != nil {
+		return nil, err
+	}
+	u.redisClient.Incr(ctx, projectFavoritesGenerationKey(actor.UserID))
+	return favorite, nil
+}
+
+func (u *projectUsecase) RemoveFavorite(c context.Context, projectID uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+
+	err := u.projectRepo.DeleteFavorite(ctx, actor.UserID, projectID)
+	if err == nil {
+		u.redisClient.Incr(ctx, projectFavoritesGenerationKey(actor.UserID))
+	}
+	return err
+}
+
+func (u *projectUsecase) ReorderFavorites(c context.Context, projectIDs []uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+
+	favorites, err := u.projectRepo.GetFavorites(ctx, actor.UserID)
+	if err != nil {
+		return err
+	}
+	starred := make(map[uint]bool, len(favorites))
+	for _, favorite := range favorites {
+		starred[favorite.ProjectID] = true
+	}
+	seen := make(map[uint]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		if !starred[id] {
+			return fmt.Errorf("%w: project %d is not in your favorites", domain.ErrInvalidInput, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: project %d is listed twice", domain.ErrInvalidInput, id)
+		}
+		seen[id] = true
+	}
+
+	// Favorites left out of the request keep their relative order after the listed ones
+	order := append([]uint(nil), projectIDs...)
+	for _, favorite := range favorites {
+		if !seen[favorite.ProjectID] {
+			order = append(order, favorite.ProjectID)
+		}
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.projectRepo.SetFavoritePositions(ctx, actor.UserID, order)
+	})
+	if err == nil {
+		u.redisClient.Incr(ctx, projectFavoritesGenerationKey(actor.UserID))
 	}
 	return err
 }
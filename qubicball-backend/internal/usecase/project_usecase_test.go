@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+func TestCloneTasks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	start := day(3)
+	estimate := 90
+	tasks := []domain.Task{
+		{
+			ID: 10, Title: "Design", Status: domain.TaskStatusCompleted, Rank: "i",
+			DueDate: day(12), StartDate: &start, EstimateMinutes: &estimate, LoggedMinutes: 45,
+			AssigneeID: uintPtr(1), Assignees: []domain.User{{ID: 1}, {ID: 2}, {ID: 3}},
+			CustomFields: []domain.CustomFieldValue{{FieldID: 7, Value: domain.JSONValue(`"high"`)}},
+			Labels:       []domain.Label{{ID: 5}},
+		},
+		{ID: 11, Title: "Build", DueDate: day(10), ParentID: uintPtr(10)},
+		{ID: 12, Title: "Unscheduled"},
+	}
+	opts := domain.ProjectCloneOptions{
+		StartDate:   func() *time.Time { d := day(20); return &d }(),
+		AssigneeMap: map[uint]uint{1: 0, 2: 9},
+	}
+
+	clones := cloneTasks(tasks, 99, "Todo", map[uint]uint{7: 70}, map[uint]uint{5: 50}, opts)
+	if len(clones) != len(tasks) {
+		t.Fatalf("got %d clones, want %d", len(clones), len(tasks))
+	}
+
+	design := clones[0]
+	if design.ProjectID != 99 || design.Status != "Todo" || design.Rank != "i" || design.ID != 0 {
+		t.Errorf("design clone = project %d, status %q, rank %q, id %d", design.ProjectID, design.Status, design.Rank, design.ID)
+	}
+	// The earliest due date, day 10, moves to day 20
+	if !design.DueDate.Equal(day(22)) || design.StartDate == nil || !design.StartDate.Equal(day(13)) {
+		t.Errorf("design dates = %v, %v", design.DueDate, design.StartDate)
+	}
+	if !clones[1].DueDate.Equal(day(20)) || !clones[2].DueDate.IsZero() {
+		t.Errorf("due dates = %v, %v", clones[1].DueDate, clones[2].DueDate)
+	}
+	if design.LoggedMinutes != 0 || design.RemainingMinutes == nil || *design.RemainingMinutes != estimate {
+		t.Errorf("design time = logged %d, remaining %v", design.LoggedMinutes, design.RemainingMinutes)
+	}
+	// 1 is dropped, 2 becomes 9 and takes over as primary, 3 is kept
+	if !reflect.DeepEqual(design.AssigneeIDs, []uint{9, 3}) || design.AssigneeID == nil || *design.AssigneeID != 9 {
+		t.Errorf("design assignees = %v, primary %v", design.AssigneeIDs, design.AssigneeID)
+	}
+	if len(design.CustomFields) != 1 || design.CustomFields[0].FieldID != 70 || string(design.CustomFields[0].Value) != `"high"` {
+		t.Errorf("design custom fields = %+v", design.CustomFields)
+	}
+	if !reflect.DeepEqual(design.LabelIDs, []uint{50}) {
+		t.Errorf("design labels = %v", design.LabelIDs)
+	}
+	// The hierarchy is rebuilt once the clones have IDs
+	if clones[1].ParentID != nil {
+		t.Errorf("build clone parent = %v, want nil", *clones[1].ParentID)
+	}
+	if clones[2].AssigneeID != nil || len(clones[2].AssigneeIDs) != 0 {
+		t.Errorf("unassigned clone = %v, %v", clones[2].AssigneeID, clones[2].AssigneeIDs)
+	}
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import "strings"
+
+// Ranks are base-36 fractions written with the digits below, which sort the
+// same byte-wise as numerically. A rank never ends in '0', so there is
+// always room for another rank between two distinct ones.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const (
+	// maxRankLength is what the rank column holds; a move that would need a
+	// longer rank rebalances the project first.
+	maxRankLength = 64
+	// rebalanceRankLength is the length past which the periodic job respaces
+	// a project's ranks.
+	rebalanceRankLength = 12
+)
+
+// rankBetween returns a rank that sorts strictly between a and b, where ""
+// stands for the start (a) or the end (b) of the list. a must sort before b.
+func rankBetween(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, treating a as padded with zeros
+		i := 0
+		for i < len(b) && rankDigit(a, i) == strings.IndexByte(rankDigits, b[i]) {
+			i++
+		}
+		if i > 0 {
+			rest := ""
+			if i < len(a) {
+				rest = a[i:]
+			}
+			return b[:i] + rankBetween(rest, b[i:])
+		}
+	}
+
+	lo := rankDigit(a, 0)
+	hi := len(rankDigits)
+	if b != "" {
+		hi = strings.IndexByte(rankDigits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(rankDigits[(lo+hi)/2])
+	}
+	// The first digits are adjacent: b's first digit alone will do if b goes
+	// on, otherwise extend a
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(rankDigits[lo]) + rankBetween(rest, "")
+}
+
+// rankAfter returns a short rank sorting after a, for appending to the end
+// of a list. Unlike rankBetween(a, "") it steps by a single digit, so ranks
+// stay short when tasks are added one after another.
+func rankAfter(a string) string {
+	for i := 0; i < len(a); i++ {
+		if a[i] != 'z' {
+			return a[:i] + string(rankDigits[strings.IndexByte(rankDigits, a[i])+1])
+		}
+	}
+	return a + "i"
+}
+
+// spreadRanks returns n ascending ranks spaced evenly across the whole range,
+// leaving room to insert around each of them.
+func spreadRanks(n int) []string {
+	// One digit more than needed to tell n ranks apart
+	width, span := 1, int64(len(rankDigits))
+	for span < int64(n+1) {
+		width++
+		span *= int64(len(rankDigits))
+	}
+	width++
+	span *= int64(len(rankDigits))
+
+	ranks := make([]string, n)
+	buf := make([]byte, width)
+	for i := range ranks {
+		v := int64(i+1) * span / int64(n+1)
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = rankDigits[v%int64(len(rankDigits))]
+			v /= int64(len(rankDigits))
+		}
+		ranks[i] = strings.TrimRight(string(buf), "0")
+	}
+	return ranks
+}
+
+// rankDigit returns the value of the rank's i-th digit, 0 past its end.
+func rankDigit(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+	return strings.IndexByte(rankDigits, rank[i])
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkRank fails the test unless rank sorts strictly between a and b and
+// leaves room after it.
+func checkRank(t *testing.T, a, b, rank string) {
+	t.Helper()
+	if rank <= a || (b != "" && rank >= b) {
+		t.Fatalf("rank %q does not sort between %q and %q", rank, a, b)
+	}
+	if strings.HasSuffix(rank, "0") {
+		t.Fatalf("rank %q ends in '0'", rank)
+	}
+	if strings.Trim(rank, rankDigits) != "" {
+		t.Fatalf("rank %q has digits outside the alphabet", rank)
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", "i"},
+		{"", "1", "0i"},
+		{"", "01", "00i"},
+		{"z", "", "zi"},
+		{"zz", "", "zzi"},
+		{"a", "c", "b"},
+		{"a", "b", "ai"},
+		{"y", "z", "yi"},
+		{"a1", "a2", "a1i"},
+		{"a", "a1", "a0i"},
+		{"az", "b", "azi"},
+		{"a", "b1", "b"},
+	}
+	for _, tt := range tests {
+		got := rankBetween(tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("rankBetween(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+		checkRank(t, tt.a, tt.b, got)
+	}
+}
+
+func TestRankBetweenGrowth(t *testing.T) {
+	// Inserting again and again at the same spot grows ranks one digit at a
+	// time at worst
+	tests := []struct {
+		name string
+		next func(a, b, mid string) (string, string)
+	}{
+		{"before the previous insert", func(a, _, mid string) (string, string) { return a, mid }},
+		{"after the previous insert", func(_, b, mid string) (string, string) { return mid, b }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := "a", "b"
+			for i := 0; i < 200; i++ {
+				mid := rankBetween(a, b)
+				checkRank(t, a, b, mid)
+				if longest := max(len(a), len(b)); len(mid) > longest+1 {
+					t.Fatalf("insert %d: rank %q between %q and %q grew by more than one digit", i, mid, a, b)
+				}
+				a, b = tt.next(a, b, mid)
+			}
+			if len(a) > maxRankLength || len(b) > maxRankLength {
+				t.Errorf("200 inserts outgrew the rank column: %q, %q", a, b)
+			}
+		})
+	}
+}
+
+func TestRankAfter(t *testing.T) {
+	tests := []struct {
+		a, want string
+	}{
+		{"", "i"},
+		{"a", "b"},
+		{"9", "a"},
+		{"a1", "b"},
+		{"za", "zb"},
+		{"z", "zi"},
+		{"zz", "zzi"},
+	}
+	for _, tt := range tests {
+		got := rankAfter(tt.a)
+		if got != tt.want {
+			t.Errorf("rankAfter(%q) = %q, want %q", tt.a, got, tt.want)
+		}
+		checkRank(t, tt.a, "", got)
+	}
+
+	// Appending one task after another uses up the digits from 'i' to 'z'
+	// before the rank grows by one
+	rank := ""
+	for i := 0; i < 200; i++ {
+		next := rankAfter(rank)
+		checkRank(t, rank, "", next)
+		if len(next) > 1+i/17 {
+			t.Fatalf("append %d: rank %q is longer than %d digits", i, next, 1+i/17)
+		}
+		rank = next
+	}
+}
+
+func TestSpreadRanks(t *testing.T) {
+	if got := spreadRanks(0); len(got) != 0 {
+		t.Errorf("spreadRanks(0) = %q", got)
+	}
+	if got := spreadRanks(1); len(got) != 1 || got[0] != "i" {
+		t.Errorf("spreadRanks(1) = %q, want [i]", got)
+	}
+	if got := spreadRanks(3); strings.Join(got, " ") != "9 i r" {
+		t.Errorf("spreadRanks(3) = %q, want [9 i r]", got)
+	}
+
+	for _, n := range []int{2, 35, 36, 37, 1000, 50000} {
+		ranks := spreadRanks(n)
+		if len(ranks) != n {
+			t.Fatalf("spreadRanks(%d) returned %d ranks", n, len(ranks))
+		}
+		prev := ""
+		for i, rank := range ranks {
+			checkRank(t, prev, "", rank)
+			if len(rank) > rebalanceRankLength {
+				t.Fatalf("spreadRanks(%d)[%d] = %q is past the rebalance length", n, i, rank)
+			}
+			// There is room before each rank
+			checkRank(t, prev, rank, rankBetween(prev, rank))
+			prev = rank
+		}
+	}
+}
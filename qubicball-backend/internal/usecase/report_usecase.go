@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+const maxReportDays = 366
+
+type reportUsecase struct {
+	projectRepo    domain.ProjectRepository
+	snapshotRepo   domain.SnapshotRepository
+	contextTimeout time.Duration
+}
+
+func NewReportUsecase(projectRepo domain.ProjectRepository, snapshotRepo domain.SnapshotRepository, timeout time.Duration) domain.ReportUsecase {
+	return &reportUsecase{
+		projectRepo:    projectRepo,
+		snapshotRepo:   snapshotRepo,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *reportUsecase) RecordDailySnapshots(c context.Context) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	return u.snapshotRepo.RecordDaily(ctx, time.Now())
+}
+
+type dailyCounts struct {
+	date   string
+	counts map[domain.TaskStatus]int64
+}
+
+// loadDailyCounts returns one entry per day in [from, to] that has data.
+// Days without a snapshot carry the previous day's counts forward, and today
+// uses live counts since its snapshot is taken at the end of the day.
+func (u *reportUsecase) loadDailyCounts(ctx context.Context, projectID uint, from, to time.Time) ([]dailyCounts, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidInput)
+	}
+	if to.Sub(from) > maxReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: date range cannot exceed %d days", domain.ErrInvalidInput, maxReportDays)
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := u.snapshotRepo.GetRange(ctx, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]map[domain.TaskStatus]int64)
+	for _, snapshot := range snapshots {
+		day := snapshot.Date.Format("2006-01-02")
+		if byDate[day] == nil {
+			byDate[day] = make(map[domain.TaskStatus]int64)
+		}
+		byDate[day][snapshot.Status] = snapshot.Count
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if to.Format("2006-01-02") >= today {
+		current, err := u.snapshotRepo.GetCurrent(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		live := make(map[domain.TaskStatus]int64)
+		for _, snapshot := range current {
+			live[snapshot.Status] = snapshot.Count
+		}
+		byDate[today] = live
+	}
+
+	var days []dailyCounts
+	var previous map[domain.TaskStatus]int64
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		if key > today {
+			break
+		}
+		counts, ok := byDate[key]
+		if !ok {
+			if previous == nil {
+				continue
+			}
+			counts = previous
+		}
+		days = append(days, dailyCounts{date: key, counts: counts})
+		previous = counts
+	}
+	return days, nil
+}
+
+func (u *reportUsecase) GetBurndown(c context.Context, projectID uint, from, to time.Time) (*domain.Burndown, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	days, err := u.loadDailyCounts(ctx, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	burndown := &domain.Burndown{
+		ProjectID: projectID,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Points:    make([]domain.BurndownPoint, 0, len(days)),
+	}
+	if len(days) == 0 {
+		return burndown, nil
+	}
+
+	// The ideal line runs from the first remaining count down to zero on To
+	firstDay, _ := time.Parse("2006-01-02", days[0].date)
+	lastDay, _ := time.Parse("2006-01-02", burndown.To)
+	span := lastDay.Sub(firstDay).Hours() / 24
+
+	var start float64
+	for i, day := range days {
+		point := domain.BurndownPoint{Date: day.date}
+		for status, count := range day.counts {
+			point.Total += count
+			if status == domain.TaskStatusCompleted {
+				point.Completed += count
+			}
+		}
+		point.Remaining = point.Total - point.Completed
+
+		if i == 0 {
+			start = float64(point.Remaining)
+		}
+		point.Ideal = start
+		if span > 0 {
+			date, _ := time.Parse("2006-01-02", day.date)
+			elapsed := date.Sub(firstDay).Hours() / 24
+			point.Ideal = float64(int64((start*(1-elapsed/span))*100+0.5)) / 100
+		}
+		burndown.Points = append(burndown.Points, point)
+	}
+	return burndown, nil
+}
+
+func (u *reportUsecase) GetCumulativeFlow(c context.Context, projectID uint, from, to time.Time) (*domain.CumulativeFlow, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	days, err := u.loadDailyCounts(ctx, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &domain.CumulativeFlow{
+		ProjectID: projectID,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Points:    make([]domain.CumulativeFlowPoint, 0, len(days)),
+	}
+
+	// Built-in statuses keep their workflow order; any others follow by name
+	statuses := []domain.TaskStatus{
+		domain.TaskStatusNotStarted,
+		domain.TaskStatusInProgress,
+		domain.TaskStatusOverdue,
+		domain.TaskStatusCompleted,
+	}
+	known := make(map[domain.TaskStatus]bool)
+	for _, status := range statuses {
+		known[status] = true
+	}
+	var extra []domain.TaskStatus
+	for _, day := range days {
+		for status := range day.counts {
+			if !known[status] {
+				known[status] = true
+				extra = append(extra, status)
+			}
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	flow.Statuses = append(statuses, extra...)
+
+	for _, day := range days {
+		counts := make(map[domain.TaskStatus]int64, len(flow.Statuses))
+		for _, status := range flow.Statuses {
+			counts[status] = day.counts[status]
+		}
+		flow.Points = append(flow.Points, domain.CumulativeFlowPoint{Date: day.date, Counts: counts})
+	}
+	return flow, nil
+}
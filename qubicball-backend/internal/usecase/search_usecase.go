@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQueryLen  = 200
+)
+
+var searchMarkup = strings.NewReplacer(domain.SearchMatchStart, "<mark>", domain.SearchMatchStop, "</mark>")
+
+type searchUsecase struct {
+	searchRepo     domain.SearchRepository
+	contextTimeout time.Duration
+}
+
+func NewSearchUsecase(searchRepo domain.SearchRepository, timeout time.Duration) domain.SearchUsecase {
+	return &searchUsecase{
+		searchRepo:     searchRepo,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *searchUsecase) Search(c context.Context, query string, opts domain.SearchOptions) (*domain.SearchResults, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: q is required", domain.ErrInvalidInput)
+	}
+	if len(query) > maxSearchQueryLen {
+		return nil, fmt.Errorf("%w: q cannot exceed %d characters", domain.ErrInvalidInput, maxSearchQueryLen)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultSearchLimit
+	}
+	if opts.Limit < 1 || opts.Limit > maxSearchLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, maxSearchLimit)
+	}
+
+	searchProjects, searchTasks := len(opts.Types) == 0, len(opts.Types) == 0
+	for _, t := range opts.Types {
+		switch t {
+		case domain.SearchResultProject:
+			searchProjects = true
+		case domain.SearchResultTask:
+			searchTasks = true
+		default:
+			return nil, fmt.Errorf("%w: unknown result type %q", domain.ErrInvalidInput, t)
+		}
+	}
+
+	// Members only see tasks assigned to them, as in the task listing
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: search requires an authenticated user", domain.ErrForbidden)
+	}
+	if actor.Role == domain.RoleMember {
+		opts.AssigneeID = &actor.UserID
+	}
+
+	var results []domain.SearchResult
+	if searchProjects {
+		projects, err := u.searchRepo.SearchProjects(ctx, query, opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, projects...)
+	}
+	if searchTasks {
+		tasks, err := u.searchRepo.SearchTasks(ctx, query, opts.AssigneeID, opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, tasks...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	for i := range results {
+		results[i].Highlight = renderHeadline(results[i].Highlight)
+		results[i].Snippet = renderHeadline(results[i].Snippet)
+	}
+	if results == nil {
+		results = []domain.SearchResult{}
+	}
+
+	return &domain.SearchResults{Query: query, Results: results}, nil
+}
+
+// renderHeadline escapes a raw headline and marks its matched terms.
+func renderHeadline(raw string) string {
+	return searchMarkup.Replace(html.EscapeString(raw))
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"testing"
+
+	"qubicball-backend/internal/domain"
+)
+
+func TestRenderHeadline(t *testing.T) {
+	start, stop := domain.SearchMatchStart, domain.SearchMatchStop
+	tests := []struct {
+		raw, want string
+	}{
+		{"plain text", "plain text"},
+		{"fix the " + start + "login" + stop + " page", "fix the <mark>login</mark> page"},
+		{start + "a" + stop + " & " + start + "b" + stop, "<mark>a</mark> &amp; <mark>b</mark>"},
+		// Markup in task text stays text, even around a match
+		{"<b>" + start + "bold" + stop + "</b>", "&lt;b&gt;<mark>bold</mark>&lt;/b&gt;"},
+	}
+	for _, tt := range tests {
+		if got := renderHeadline(tt.raw); got != tt.want {
+			t.Errorf("renderHeadline(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type sprintUsecase struct {
+	sprintRepo     domain.SprintRepository
+	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewSprintUsecase(sprintRepo domain.SprintRepository, projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.SprintUsecase {
+	return &sprintUsecase{
+		sprintRepo:     sprintRepo,
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func validateSprintDates(sprint *domain.Sprint) error {
+	if sprint.Name == "" {
+		return fmt.Errorf("%w: sprint name is required", domain.ErrInvalidInput)
+	}
+	if sprint.StartDate.IsZero() || sprint.EndDate.IsZero() {
+		return fmt.Errorf("%w: start_date and end_date are required", domain.ErrInvalidInput)
+	}
+	if !sprint.EndDate.After(sprint.StartDate) {
+		return fmt.Errorf("%w: end_date must be after start_date", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+func (u *sprintUsecase) Create(c context.Context, sprint *domain.Sprint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, sprint.ProjectID)
+	if err != nil {
+		return err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return fmt.Errorf("%w: only the project owner or an admin can create sprints", domain.ErrForbidden)
+	}
+	if err := validateSprintDates(sprint); err != nil {
+		return err
+	}
+	sprint.Status = domain.SprintStatusPlanned
+	sprint.StartedAt = nil
+	sprint.CompletedAt = nil
+
+	return u.sprintRepo.Create(ctx, sprint)
+}
+
+// get loads a sprint and checks that it belongs to projectID.
+func (u *sprintUsecase) get(ctx context.Context, projectID, id uint) (*domain.Sprint, error) {
+	sprint, err := u.sprintRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.ProjectID != projectID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return sprint, nil
+}
+
+func (u *sprintUsecase) attachProgress(ctx context.Context, sprints []domain.Sprint) error {
+	ids := make([]uint, len(sprints))
+	for i := range sprints {
+		ids[i] = sprints[i].ID
+	}
+
+	progress, err := u.sprintRepo.GetProgress(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range sprints {
+		p := progress[sprints[i].ID]
+		p.CompletionPercent = percent(p.Completed, p.Total)
+		sprints[i].Progress = p
+	}
+	return nil
+}
+
+func (u *sprintUsecase) GetByID(c context.Context, projectID, id uint) (*domain.Sprint, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	sprint, err := u.get(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sprints := []domain.Sprint{*sprint}
+	if err := u.attachProgress(ctx, sprints); err != nil {
+		return nil, err
+	}
+	return &sprints[0], nil
+}
+
+func (u *sprintUsecase) GetByProjectID(c context.Context, projectID uint) ([]domain.Sprint, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	sprints, err := u.sprintRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.attachProgress(ctx, sprints); err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+func (u *sprintUsecase) Update(c context.Context, sprint *domain.Sprint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	existing, err := u.get(ctx, sprint.ProjectID, sprint.ID)
+	if err != nil {
+		return err
+	}
+	if existing.Status == domain.SprintStatusCompleted {
+		return fmt.Errorf("%w: completed sprints cannot be edited", domain.ErrConflict)
+	}
+
+	if sprint.Name != "" {
+		existing.Name = sprint.Name
+	}
+	if sprint.Goal != "" {
+		existing.Goal = sprint.Goal
+	}
+	if !sprint.StartDate.IsZero() {
+		existing.StartDate = sprint.StartDate
+	}
+	if !sprint.EndDate.IsZero() {
+		existing.EndDate = sprint.EndDate
+	}
+	if err := validateSprintDates(existing); err != nil {
+		return err
+	}
+
+	if err := u.sprintRepo.UpdateDetails(ctx, existing); err != nil {
+		return err
+	}
+	*sprint = *existing
+	return nil
+}
+
+func (u *sprintUsecase) Delete(c context.Context, projectID, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.get(ctx, projectID, id); err != nil {
+		return err
+	}
+
+	// Tasks of a deleted sprint fall back to the backlog
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.taskRepo.ClearSprint(ctx, id); err != nil {
+			return err
+		}
+		return u.sprintRepo.Delete(ctx, id)
+	})
+	if err == nil {
+		invalidateTaskCaches(ctx, u.redisClient, projectID)
+	}
+	return err
+}
+
+func (u *sprintUsecase) Start(c context.Context, projectID, id uint) (*domain.Sprint, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	sprint, err := u.get(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.Status != domain.SprintStatusPlanned {
+		return nil, fmt.Errorf("%w: only planned sprints can be started, sprint is %s", domain.ErrConflict, sprint.Status)
+	}
+
+	// The lock keeps two starts from both seeing no active sprint
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.sprintRepo.LockActive(ctx, projectID); err != nil {
+			return err
+		}
+		active, err := u.sprintRepo.GetActiveByProjectID(ctx, projectID)
+		if err == nil {
+			return fmt.Errorf("%w: sprint %q is already active in this project", domain.ErrConflict, active.Name)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		sprint.Status = domain.SprintStatusActive
+		sprint.StartedAt = &now
+		return u.sprintRepo.UpdateStatus(ctx, sprint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sprint, nil
+}
+
+func (u *sprintUsecase) Complete(c context.Context, projectID, id uint, carryOverTo *uint) (*domain.SprintCompletion, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	sprint, err := u.get(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.Status != domain.SprintStatusActive {
+		return nil, fmt.Errorf("%w: only active sprints can be completed, sprint is %s", domain.ErrConflict, sprint.Status)
+	}
+
+	if carryOverTo != nil {
+		if *carryOverTo == id {
+			return nil, fmt.Errorf("%w: cannot carry tasks over into the sprint being completed", domain.ErrInvalidInput)
+		}
+		target, err := u.get(ctx, projectID, *carryOverTo)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: carry-over sprint %d not found in this project", domain.ErrInvalidInput, *carryOverTo)
+			}
+			return nil, err
+		}
+		if target.Status == domain.SprintStatusCompleted {
+			return nil, fmt.Errorf("%w: cannot carry tasks over into a completed sprint", domain.ErrInvalidInput)
+		}
+	}
+
+	result := &domain.SprintCompletion{Sprint: sprint, CarriedOverTo: carryOverTo}
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		moved, err := u.taskRepo.MoveUnfinishedFromSprint(ctx, id, carryOverTo)
+		if err != nil {
+			return err
+		}
+		result.CarriedOver = moved
+
+		now := time.Now()
+		sprint.Status = domain.SprintStatusCompleted
+		sprint.CompletedAt = &now
+		return u.sprintRepo.UpdateStatus(ctx, sprint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, projectID)
+
+	sprints := []domain.Sprint{*sprint}
+	if err := u.attachProgress(ctx, sprints); err != nil {
+		return nil, err
+	}
+	result.Sprint = &sprints[0]
+	result.CompletedTasks = sprints[0].Progress.Completed
+	return result, nil
+}
+
+func (u *sprintUsecase) AssignTasks(c context.Context, projectID, id uint, taskIDs []uint) (int64, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	sprint, err := u.get(ctx, projectID, id)
+	if err != nil {
+		return 0, err
+	}
+	if sprint.Status == domain.SprintStatusCompleted {
+		return 0, fmt.Errorf("%w: cannot add tasks to a completed sprint", domain.ErrConflict)
+	}
+	if len(taskIDs) == 0 {
+		return 0, fmt.Errorf("%w: task_ids is required", domain.ErrInvalidInput)
+	}
+
+	assigned, err := u.taskRepo.SetSprint(ctx, projectID, taskIDs, &id)
+	if err == nil {
+		invalidateTaskCaches(ctx, u.redisClient, projectID)
+	}
+	return assigned, err
+}
+
+func (u *sprintUsecase) RemoveTask(c context.Context, projectID, id, taskID uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.get(ctx, projectID, id); err != nil {
+		return err
+	}
+
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if task.SprintID == nil || *task.SprintID != id {
+		return gorm.ErrRecordNotFound
+	}
+
+	_, err = u.taskRepo.SetSprint(ctx, projectID, []uint{taskID}, nil)
+	if err == nil {
+		invalidateTaskCaches(ctx, u.redisClient, projectID)
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+const (
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
+)
+
+type taskActivityUsecase struct {
+	activityRepo   domain.TaskActivityRepository
+	taskRepo       domain.TaskRepository
+	projectRepo    domain.ProjectRepository
+	contextTimeout time.Duration
+}
+
+func NewTaskActivityUsecase(activityRepo domain.TaskActivityRepository, taskRepo domain.TaskRepository, projectRepo domain.ProjectRepository, timeout time.Duration) domain.TaskActivityUsecase {
+	return &taskActivityUsecase{
+		activityRepo:   activityRepo,
+		taskRepo:       taskRepo,
+		projectRepo:    projectRepo,
+		contextTimeout: timeout,
+	}
+}
+
+func validateActivityQuery(query *domain.TaskActivityQuery) error {
+	if query.Limit == 0 {
+		query.Limit = defaultActivityPageSize
+	}
+	if query.Limit < 1 || query.Limit > maxActivityPageSize {
+		return fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, maxActivityPageSize)
+	}
+	return nil
+}
+
+// activityPage trims the extra entry fetched to detect a following page.
+func activityPage(activities []domain.TaskActivity, limit int) *domain.TaskActivityPage {
+	page := &domain.TaskActivityPage{Activities: activities}
+	if len(activities) > limit {
+		page.Activities = activities[:limit]
+		page.NextCursor = strconv.FormatUint(uint64(activities[limit-1].ID), 10)
+	}
+	return page
+}
+
+func (u *taskActivityUsecase) GetByTaskID(c context.Context, taskID uint, query domain.TaskActivityQuery) (*domain.TaskActivityPage, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := validateActivityQuery(&query); err != nil {
+		return nil, err
+	}
+	if _, err := u.taskRepo.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	query.Limit++
+	activities, err := u.activityRepo.GetByTaskID(ctx, taskID, query)
+	if err != nil {
+		return nil, err
+	}
+	return activityPage(activities, limit), nil
+}
+
+func (u *taskActivityUsecase) GetByProjectID(c context.Context, projectID uint, query domain.TaskActivityQuery) (*domain.TaskActivityPage, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := validateActivityQuery(&query); err != nil {
+		return nil, err
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+	query.AssigneeID = nil
+	if actor, ok := domain.ActorFromContext(ctx); ok && actor.Role == domain.RoleMember {
+		query.AssigneeID = &actor.UserID
+	}
+	limit := query.Limit
+	query.Limit++
+	activities, err := u.activityRepo.GetByProjectID(ctx, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+	return activityPage(activities, limit), nil
+}
+
+// newTaskActivity starts an entry for the task attributed to the request's
+// actor, if any.
+func newTaskActivity(ctx context.Context, taskID, projectID uint, action domain.TaskActivityAction) domain.TaskActivity {
+	activity := domain.TaskActivity{TaskID: taskID, ProjectID: projectID, Action: action}
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		activity.ActorID = &actor.UserID
+	}
+	return activity
+}
+
+// diffTask returns an update entry for each tracked field that differs
+// between before and after.
+func diffTask(ctx context.Context, before, after *domain.Task) []domain.TaskActivity {
+	fields := []struct {
+		name     string
+		old, new *string
+	}{
+		{"title", activityString(before.Title), activityString(after.Title)},
+		{"description", activityString(before.Description), activityString(after.Description)},
+		{"status", activityString(string(before.Status)), activityString(string(after.Status))},
+		{"priority", activityString(string(before.Priority)), activityString(string(after.Priority))},
+		{"due_date", activityTime(&before.DueDate), activityTime(&after.DueDate)},
+		{"start_date", activityTime(before.StartDate), activityTime(after.StartDate)},
+		{"duration_days", activityInt(before.DurationDays), activityInt(after.DurationDays)},
+		{"estimate_minutes", activityInt(before.EstimateMinutes), activityInt(after.EstimateMinutes)},
+		{"remaining_minutes", activityInt(before.RemainingMinutes), activityInt(after.RemainingMinutes)},
+		{"assignee_id", activityID(before.AssigneeID), activityID(after.AssigneeID)},
+		{"assignee_ids", activityIDs(before.AssigneeSet()), activityIDs(after.AssigneeSet())},
+		{"label_ids", activityIDs(before.LabelSet()), activityIDs(after.LabelSet())},
+		{"parent_id", activityID(before.ParentID), activityID(after.ParentID)},
+		{"project_id", activityID(&before.ProjectID), activityID(&after.ProjectID)},
+	}
+
+	var activities []domain.TaskActivity
+	for _, field := range fields {
+		if equalActivityValues(field.old, field.new) {
+			continue
+		}
+		activity := newTaskActivity(ctx, after.ID, after.ProjectID, domain.TaskActivityUpdated)
+		activity.Field = field.name
+		activity.OldValue = field.old
+		activity.NewValue = field.new
+		activities = append(activities, activity)
+	}
+	return activities
+}
+
+func equalActivityValues(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func activityString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func activityTime(value *time.Time) *string {
+	if value == nil || value.IsZero() {
+		return nil
+	}
+	formatted := value.UTC().Format(time.RFC3339)
+	return &formatted
+}
+
+func activityInt(value *int) *string {
+	if value == nil {
+		return nil
+	}
+	formatted := strconv.Itoa(*value)
+	return &formatted
+}
+
+func activityID(value *uint) *string {
+	if value == nil {
+		return nil
+	}
+	formatted := strconv.FormatUint(uint64(*value), 10)
+	return &formatted
+}
+
+// activityIDs formats a set of IDs in ascending order, so equal sets compare
+// equal.
+func activityIDs(ids []uint) *string {
+	if len(ids) == 0 {
+		return nil
+	}
+	sorted := append([]uint(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	parts := make([]string, len(sorted))
+	for i, id := range sorted {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	formatted := strings.Join(parts, ",")
+	return &formatted
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type taskDependencyUsecase struct {
+	dependencyRepo domain.TaskDependencyRepository
+	taskRepo       domain.TaskRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewTaskDependencyUsecase(dependencyRepo domain.TaskDependencyRepository, taskRepo domain.TaskRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.TaskDependencyUsecase {
+	return &taskDependencyUsecase{
+		dependencyRepo: dependencyRepo,
+		taskRepo:       taskRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *taskDependencyUsecase) AddBlocker(c context.Context, taskID, blockerID uint) (*domain.TaskDependencies, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if taskID == blockerID {
+		return nil, fmt.Errorf("%w: a task cannot block itself", domain.ErrInvalidInput)
+	}
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	blocker, err := u.taskRepo.GetByID(ctx, blockerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: blocking task %d not found", domain.ErrInvalidInput, blockerID)
+		}
+		return nil, err
+	}
+
+	dependency := &domain.TaskDependency{BlockerID: blockerID, BlockedID: taskID}
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		dependency.CreatedByID = &actor.UserID
+	}
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Concurrent links could each pass the cycle check and close a cycle
+		// together
+		if err := u.dependencyRepo.LockGraph(ctx); err != nil {
+			return err
+		}
+		edges, err := u.dependencyRepo.GetBlockers(ctx, []uint{taskID})
+		if err != nil {
+			return err
+		}
+		for _, edge := range edges {
+			if edge.BlockerID == blockerID {
+				return fmt.Errorf("%w: task %d already blocks task %d", domain.ErrConflict, blockerID, taskID)
+			}
+		}
+
+		// The new edge closes a cycle if the task already leads to its blocker
+		cycle, err := u.dependencyRepo.Reaches(ctx, taskID, blockerID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("%w: task %d already depends on task %d, linking them would create a cycle", domain.ErrInvalidInput, blockerID, taskID)
+		}
+		return u.dependencyRepo.Create(ctx, dependency)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID, blocker.ProjectID)
+
+	return u.dependencies(ctx, taskID)
+}
+
+func (u *taskDependencyUsecase) RemoveBlocker(c context.Context, taskID, blockerID uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if err := u.dependencyRepo.Delete(ctx, blockerID, taskID); err != nil {
+		return err
+	}
+
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+	if blocker, err := u.taskRepo.GetByID(ctx, blockerID); err == nil {
+		invalidateTaskCaches(ctx, u.redisClient, blocker.ProjectID)
+	}
+	return nil
+}
+
+func (u *taskDependencyUsecase) GetDependencies(c context.Context, taskID uint) (*domain.TaskDependencies, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.taskRepo.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return u.dependencies(ctx, taskID)
+}
+
+func (u *taskDependencyUsecase) dependencies(ctx context.Context, taskID uint) (*domain.TaskDependencies, error) {
+	result := &domain.TaskDependencies{BlockedBy: []domain.TaskRef{}, Blocks: []domain.TaskRef{}}
+
+	blockers, err := u.dependencyRepo.GetBlockers(ctx, []uint{taskID})
+	if err != nil {
+		return nil, err
+	}
+	for _, edge := range blockers {
+		result.BlockedBy = append(result.BlockedBy, edge.Task)
+	}
+
+	blocked, err := u.dependencyRepo.GetBlocked(ctx, []uint{taskID})
+	if err != nil {
+		return nil, err
+	}
+	for _, edge := range blocked {
+		result.Blocks = append(result.Blocks, edge.Task)
+	}
+	return result, nil
+}
@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// recurrenceBatchSize bounds how many series are advanced per pass of the
+// scheduler.
+const recurrenceBatchSize = 100
+
+// recurrenceScheduler moves series on to their next occurrence. It is
+// shared with taskUsecase, which advances a series when its current task is
+// completed. Callers run it within a transaction.
+type recurrenceScheduler struct {
+	taskRepo       domain.TaskRepository
+	projectRepo    domain.ProjectRepository
+	recurrenceRepo domain.TaskRecurrenceRepository
+	activityRepo   domain.TaskActivityRepository
+}
+
+// advance creates the occurrence that follows fromTaskID, or ends the series
+// when there is none. Occurrences due by notBefore are skipped rather than
+// created, so a series that fell far behind catches up in one step. It
+// returns the created task, or nil when nothing was created, including when
+// the series already moved past fromTaskID.
+func (s *recurrenceScheduler) advance(ctx context.Context, recurrenceID, fromTaskID uint, notBefore time.Time) (*domain.Task, error) {
+	series, err := s.recurrenceRepo.GetForUpdate(ctx, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	if series.EndedAt != nil || series.CurrentTaskID != fromTaskID {
+		return nil, nil
+	}
+	rule, err := series.ParsedRule()
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.taskRepo.GetByID(ctx, fromTaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	due, ok := rule.Next(series.StartDate, current.DueDate, series.Occurrences)
+	for ok && !due.After(notBefore) {
+		// Skipped occurrences count towards COUNT, as with Skip
+		series.Occurrences++
+		due, ok = rule.Next(series.StartDate, due, series.Occurrences)
+	}
+	if !ok {
+		return nil, s.end(ctx, series)
+	}
+	project, err := s.projectRepo.GetByID(ctx, current.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.LockRanks(ctx, current.ProjectID); err != nil {
+		return nil, err
+	}
+	rank, err := appendRank(ctx, s.taskRepo, current.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &domain.Task{
+		Title:        current.Title,
+		Description:  current.Description,
+		Status:       project.EffectiveWorkflow().Initial,
+		Priority:     current.Priority,
+		Rank:         rank,
+		DueDate:      due,
+		DurationDays: current.DurationDays,
+		// Each occurrence starts with the full estimate
+		EstimateMinutes:  current.EstimateMinutes,
+		RemainingMinutes: current.EstimateMinutes,
+		ProjectID:        current.ProjectID,
+		AssigneeID:       current.AssigneeID,
+		AssigneeIDs:      current.AssigneeSet(),
+		LabelIDs:         current.LabelSet(),
+		ParentID:         current.ParentID,
+		RecurrenceID:     &series.ID,
+	}
+	if current.StartDate != nil {
+		start := current.StartDate.Add(due.Sub(current.DueDate))
+		task.StartDate = &start
+	}
+	if err := s.taskRepo.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	activity := newTaskActivity(ctx, task.ID, task.ProjectID, domain.TaskActivityCreated)
+	if err := s.activityRepo.CreateBatch(ctx, []domain.TaskActivity{activity}); err != nil {
+		return nil, err
+	}
+
+	series.CurrentTaskID = task.ID
+	series.Occurrences++
+	series.NextDueDate = nextDueDate(rule, series, due)
+	return task, s.recurrenceRepo.Update(ctx, series)
+}
+
+// stop ends the series if taskID is its current occurrence, e.g. because
+// the task was deleted.
+func (s *recurrenceScheduler) stop(ctx context.Context, recurrenceID, taskID uint) error {
+	series, err := s.recurrenceRepo.GetForUpdate(ctx, recurrenceID)
+	if err != nil {
+		return err
+	}
+	if series.EndedAt != nil || series.CurrentTaskID != taskID {
+		return nil
+	}
+	return s.end(ctx, series)
+}
+
+func (s *recurrenceScheduler) end(ctx context.Context, series *domain.TaskRecurrence) error {
+	now := time.Now()
+	series.EndedAt = &now
+	series.NextDueDate = nil
+	return s.recurrenceRepo.Update(ctx, series)
+}
+
+// nextDueDate is when the occurrence after the current one falls, or nil if
+// there is none.
+func nextDueDate(rule *domain.RecurrenceRule, series *domain.TaskRecurrence, currentDue time.Time) *time.Time {
+	next, ok := rule.Next(series.StartDate, currentDue, series.Occurrences)
+	if !ok {
+		return nil
+	}
+	return &next
+}
+
+type taskRecurrenceUsecase struct {
+	scheduler      *recurrenceScheduler
+	taskRepo       domain.TaskRepository
+	recurrenceRepo domain.TaskRecurrenceRepository
+	activityRepo   domain.TaskActivityRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewTaskRecurrenceUsecase(recurrenceRepo domain.TaskRecurrenceRepository, taskRepo domain.TaskRepository, projectRepo domain.ProjectRepository, activityRepo domain.TaskActivityRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.TaskRecurrenceUsecase {
+	return &taskRecurrenceUsecase{
+		scheduler: &recurrenceScheduler{
+			taskRepo:       taskRepo,
+			projectRepo:    projectRepo,
+			recurrenceRepo: recurrenceRepo,
+			activityRepo:   activityRepo,
+		},
+		taskRepo:       taskRepo,
+		recurrenceRepo: recurrenceRepo,
+		activityRepo:   activityRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *taskRecurrenceUsecase) SetRecurrence(c context.Context, taskID uint, value string, endAfter int) (*domain.TaskRecurrence, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	rule, err := domain.ParseRecurrenceRule(value)
+	if err != nil {
+		return nil, err
+	}
+	if endAfter < 0 {
+		return nil, fmt.Errorf("%w: end_after must be positive", domain.ErrInvalidInput)
+	}
+	if endAfter > 0 {
+		if rule.Count > 0 || rule.Until != nil {
+			return nil, fmt.Errorf("%w: end_after cannot be combined with COUNT or UNTIL", domain.ErrInvalidInput)
+		}
+		rule.Count = endAfter
+	}
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.DueDate.IsZero() {
+		return nil, fmt.Errorf("%w: a recurring task needs a due date", domain.ErrInvalidInput)
+	}
+
+	series := &domain.TaskRecurrence{
+		Rule:          rule.String(),
+		StartDate:     task.DueDate,
+		Occurrences:   1,
+		CurrentTaskID: task.ID,
+	}
+	series.NextDueDate = nextDueDate(rule, series, task.DueDate)
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if task.RecurrenceID != nil {
+			if err := u.scheduler.stop(ctx, *task.RecurrenceID, task.ID); err != nil {
+				return err
+			}
+		}
+		if err := u.recurrenceRepo.Create(ctx, series); err != nil {
+			return err
+		}
+		return u.taskRepo.SetRecurrence(ctx, task.ID, &series.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+	return series, nil
+}
+
+func (u *taskRecurrenceUsecase) GetRecurrence(c context.Context, taskID uint) (*domain.TaskRecurrence, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.RecurrenceID == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return u.recurrenceRepo.GetByID(ctx, *task.RecurrenceID)
+}
+
+func (u *taskRecurrenceUsecase) Skip(c context.Context, taskID uint) (*domain.TaskRecurrence, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.RecurrenceID == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var series *domain.TaskRecurrence
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		series, err = u.recurrenceRepo.GetForUpdate(ctx, *task.RecurrenceID)
+		if err != nil {
+			return err
+		}
+		if series.EndedAt != nil {
+			return fmt.Errorf("%w: the series has ended", domain.ErrConflict)
+		}
+		if series.CurrentTaskID != task.ID {
+			return fmt.Errorf("%w: only the series' current occurrence can be skipped", domain.ErrConflict)
+		}
+		// Reloaded under the series lock for an up-to-date version
+		task, err = u.taskRepo.GetByID(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		rule, err := series.ParsedRule()
+		if err != nil {
+			return err
+		}
+		due, ok := rule.Next(series.StartDate, task.DueDate, series.Occurrences)
+		if !ok {
+			return fmt.Errorf("%w: the series has no further occurrences to skip to", domain.ErrConflict)
+		}
+
+		// The task takes the next occurrence's place, so the skipped one
+		// still counts towards COUNT
+		before := *task
+		if task.StartDate != nil {
+			start := task.StartDate.Add(due.Sub(task.DueDate))
+			task.StartDate = &start
+		}
+		task.DueDate = due
+		if err := u.taskRepo.Update(ctx, task); err != nil {
+			return err
+		}
+		if err := u.activityRepo.CreateBatch(ctx, diffTask(ctx, &before, task)); err != nil {
+			return err
+		}
+
+		series.Occurrences++
+		series.NextDueDate = nextDueDate(rule, series, due)
+		return u.recurrenceRepo.Update(ctx, series)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+	return series, nil
+}
+
+func (u *taskRecurrenceUsecase) Stop(c context.Context, taskID uint) (*domain.TaskRecurrence, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.RecurrenceID == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var series *domain.TaskRecurrence
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		series, err = u.recurrenceRepo.GetForUpdate(ctx, *task.RecurrenceID)
+		if err != nil || series.EndedAt != nil {
+			return err
+		}
+		return u.scheduler.end(ctx, series)
+	})
+	return series, err
+}
+
+func (u *taskRecurrenceUsecase) GenerateDue(c context.Context) (int, error) {
+	created := 0
+	for {
+		processed, n, err := u.generateBatch(c)
+		created += n
+		if err != nil || processed == 0 {
+			return created, err
+		}
+	}
+}
+
+// generateBatch advances one batch of due series to their first occurrence
+// after now; missed occurrences are skipped rather than backfilled.
+func (u *taskRecurrenceUsecase) generateBatch(c context.Context) (processed, created int, err error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	ids, err := u.recurrenceRepo.GetDueIDs(ctx, now, recurrenceBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, id := range ids {
+		var task *domain.Task
+		err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			series, err := u.recurrenceRepo.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			task, err = u.scheduler.advance(ctx, id, series.CurrentTaskID, now)
+			return err
+		})
+		if err != nil {
+			return processed, created, err
+		}
+		processed++
+		if task != nil {
+			created++
+			invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+		}
+	}
+	return processed, created, nil
+}
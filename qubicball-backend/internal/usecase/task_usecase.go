@@ -3,23 +3,61 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"qubicball-backend/internal/domain"
 
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
+// purgeBatchSize bounds how many deleted tasks are purged per transaction.
+const purgeBatchSize = 100
+
+// maxTaskAssignees bounds how many users a task can be assigned to.
+const maxTaskAssignees = 20
+
+// maxTaskLabels bounds how many labels a task can have.
+const maxTaskLabels = 20
+
 type taskUsecase struct {
 	taskRepo       domain.TaskRepository
+	projectRepo    domain.ProjectRepository
+	userRepo       domain.UserRepository
+	fieldRepo      domain.CustomFieldRepository
+	labelRepo      domain.LabelRepository
+	dependencyRepo domain.TaskDependencyRepository
+	attachmentRepo domain.AttachmentRepository
+	activityRepo   domain.TaskActivityRepository
+	recurrences    *recurrenceScheduler
+	blobStore      domain.BlobStore
+	transactor     domain.Transactor
 	redisClient    *redis.Client
 	contextTimeout time.Duration
 }
 
-func NewTaskUsecase(taskRepo domain.TaskRepository, redisClient *redis.Client, timeout time.Duration) domain.TaskUsecase {
+func NewTaskUsecase(taskRepo domain.TaskRepository, projectRepo domain.ProjectRepository, userRepo domain.UserRepository, fieldRepo domain.CustomFieldRepository, labelRepo domain.LabelRepository, dependencyRepo domain.TaskDependencyRepository, attachmentRepo domain.AttachmentRepository, activityRepo domain.TaskActivityRepository, recurrenceRepo domain.TaskRecurrenceRepository, blobStore domain.BlobStore, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.TaskUsecase {
 	return &taskUsecase{
 		taskRepo:       taskRepo,
+		projectRepo:    projectRepo,
+		userRepo:       userRepo,
+		fieldRepo:      fieldRepo,
+		labelRepo:      labelRepo,
+		dependencyRepo: dependencyRepo,
+		attachmentRepo: attachmentRepo,
+		activityRepo:   activityRepo,
+		recurrences: &recurrenceScheduler{
+			taskRepo:       taskRepo,
+			projectRepo:    projectRepo,
+			recurrenceRepo: recurrenceRepo,
+			activityRepo:   activityRepo,
+		},
+		blobStore:      blobStore,
+		transactor:     transactor,
 		redisClient:    redisClient,
 		contextTimeout: timeout,
 	}
@@ -29,9 +67,84 @@ func (u *taskUsecase) Create(c context.Context, task *domain.Task) error {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	err := u.taskRepo.Create(ctx, task)
+	// Custom field values come in CustomFieldValues, and series are written
+	// by TaskRecurrenceUsecase
+	task.CustomFields = nil
+	task.RecurrenceID = nil
+	task.Assignees, task.Watchers, task.Labels = nil, nil, nil
+	// Logged time only comes from time entries
+	task.LoggedMinutes = 0
+	if task.EstimateMinutes != nil && task.RemainingMinutes == nil {
+		remaining := *task.EstimateMinutes
+		task.RemainingMinutes = &remaining
+	}
+	if err := validateSchedule(task); err != nil {
+		return err
+	}
+	// The first of several assignees becomes the primary one
+	if task.AssigneeID == nil && len(task.AssigneeIDs) > 0 {
+		task.AssigneeID = &task.AssigneeIDs[0]
+	}
+	task.AssigneeIDs = task.AssigneeSet()
+	if err := u.validateAssignees(ctx, task.AssigneeIDs); err != nil {
+		return err
+	}
+	project, err := u.projectRepo.GetByID(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if task.LabelIDs, err = u.validateLabels(ctx, task.ProjectID, task.LabelIDs); err != nil {
+		return err
+	}
+	fields, err := u.fieldRepo.GetByProjectID(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if task.CustomFields, err = newTaskFieldValues(ctx, u.userRepo, fields, task.CustomFieldValues); err != nil {
+		return err
+	}
+	task.CustomFieldValues = nil
+	workflow := project.EffectiveWorkflow()
+	if task.Status == "" {
+		task.Status = workflow.Initial
+	} else if err := workflow.CheckStatus(task.Status); err != nil {
+		return err
+	}
+	if task.Priority == "" {
+		task.Priority = domain.TaskPriorityMedium
+	} else if !task.Priority.Valid() {
+		return fmt.Errorf("%w: invalid priority %q", domain.ErrInvalidInput, task.Priority)
+	}
+	if task.ParentID != nil && *task.ParentID == 0 {
+		task.ParentID = nil
+	}
+	if task.ParentID != nil {
+		if err := u.validateParent(ctx, task, *task.ParentID); err != nil {
+			return err
+		}
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// New tasks go to the end of the project's manual order
+		if err := u.taskRepo.LockRanks(ctx, task.ProjectID); err != nil {
+			return err
+		}
+		if err := u.checkWIPLimit(ctx, project, task.Status); err != nil {
+			return err
+		}
+		rank, err := appendRank(ctx, u.taskRepo, task.ProjectID)
+		if err != nil {
+			return err
+		}
+		task.Rank = rank
+		if err := u.taskRepo.Create(ctx, task); err != nil {
+			return err
+		}
+		activity := newTaskActivity(ctx, task.ID, task.ProjectID, domain.TaskActivityCreated)
+		return u.activityRepo.CreateBatch(ctx, []domain.TaskActivity{activity})
+	})
 	if err == nil {
-		u.redisClient.Del(ctx, fmt.Sprintf("tasks:project:%d", task.ProjectID))
+		invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
 	}
 	return err
 }
@@ -40,31 +153,81 @@ func (u *taskUsecase) GetByID(c context.Context, id uint) (*domain.Task, error)
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	return u.taskRepo.GetByID(ctx, id)
+	task, err := u.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []domain.Task{*task}
+	if err := u.attachRelations(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 200
+)
+
+// validateTaskQuery checks paging and sort options and fills in defaults.
+func validateTaskQuery(query *domain.TaskQuery) error {
+	if query.Sort.Field == "" {
+		query.Sort.Field = domain.TaskSortCreated
+	}
+	if !query.Sort.Field.Valid() {
+		return fmt.Errorf("%w: cannot sort by %q", domain.ErrInvalidInput, query.Sort.Field)
+	}
+	if query.Limit < 0 || query.Limit > maxTaskPageSize {
+		return fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, maxTaskPageSize)
+	}
+	if query.Cursor != "" && query.Limit == 0 {
+		query.Limit = defaultTaskPageSize
+	}
+	return nil
 }
 
-func (u *taskUsecase) GetByProjectID(c context.Context, projectID uint) ([]domain.Task, error) {
+func (u *taskUsecase) GetByProjectID(c context.Context, projectID uint, query domain.TaskQuery, customFields map[uint]string) (*domain.TaskPage, error) {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	cacheKey := fmt.Sprintf("tasks:project:%d", projectID)
-	cachedTasks, err := u.redisClient.Get(ctx, cacheKey).Result()
+	// A project's tasks are listed in their manual order unless asked otherwise
+	if query.Sort.Field == "" {
+		query.Sort.Field = domain.TaskSortRank
+	}
+	if err := validateTaskQuery(&query); err != nil {
+		return nil, err
+	}
+	filters, err := resolveCustomFieldFilters(ctx, u.fieldRepo, projectID, customFields)
+	if err != nil {
+		return nil, err
+	}
+	query.Filter.CustomFields = filters
+
+	// One hash per project holds every cached query, so invalidation stays a
+	// single DEL
+	cacheKey := projectTasksKey(projectID)
+	field := query.CacheKey()
+	cachedPage, err := u.redisClient.HGet(ctx, cacheKey, field).Result()
 	if err == nil {
-		var tasks []domain.Task
-		if err := json.Unmarshal([]byte(cachedTasks), &tasks); err == nil {
-			return tasks, nil
+		var page domain.TaskPage
+		if err := json.Unmarshal([]byte(cachedPage), &page); err == nil {
+			return &page, nil
 		}
 	}
 
-	tasks, err := u.taskRepo.GetByProjectID(ctx, projectID)
+	page, err := u.taskRepo.GetByProjectID(ctx, projectID, query)
 	if err != nil {
 		return nil, err
 	}
+	if err := u.attachRelations(ctx, page.Tasks); err != nil {
+		return nil, err
+	}
 
-	jsonTasks, _ := json.Marshal(tasks)
-	u.redisClient.Set(ctx, cacheKey, jsonTasks, time.Minute*5)
+	jsonPage, _ := json.Marshal(page)
+	u.redisClient.HSet(ctx, cacheKey, field, jsonPage)
+	u.redisClient.Expire(ctx, cacheKey, time.Minute*5)
 
-	return tasks, nil
+	return page, nil
 }
 
 func (u *taskUsecase) Update(c context.Context, task *domain.Task) error {
@@ -77,6 +240,9 @@ func (u *taskUsecase) Update(c context.Context, task *domain.Task) error {
 		return err
 	}
 
+	before := *existingTask
+	previousStatus := existingTask.Status
+
 	// 2. Merge changes (only update non-zero or specific fields)
 	if task.Title != "" {
 		existingTask.Title = task.Title
@@ -87,22 +253,126 @@ func (u *taskUsecase) Update(c context.Context, task *domain.Task) error {
 	if task.Status != "" {
 		existingTask.Status = task.Status
 	}
+	if task.Priority != "" {
+		if !task.Priority.Valid() {
+			return fmt.Errorf("%w: invalid priority %q", domain.ErrInvalidInput, task.Priority)
+		}
+		existingTask.Priority = task.Priority
+	}
 	// Check if DueDate is not zero time
 	if !task.DueDate.IsZero() {
 		existingTask.DueDate = task.DueDate
 	}
-	if task.AssigneeID != nil {
-		existingTask.AssigneeID = task.AssigneeID
+	assigneesChanged := mergeAssignees(existingTask, task)
+	if assigneesChanged {
+		if err := u.validateAssignees(ctx, existingTask.AssigneeIDs); err != nil {
+			return err
+		}
+	}
+	labelsChanged := task.LabelIDs != nil
+	if labelsChanged {
+		if existingTask.LabelIDs, err = u.validateLabels(ctx, existingTask.ProjectID, task.LabelIDs); err != nil {
+			return err
+		}
+	}
+	if err := mergeSchedule(existingTask, task); err != nil {
+		return err
+	}
+	if task.EstimateMinutes != nil {
+		existingTask.EstimateMinutes = task.EstimateMinutes
+		// A first estimate starts out entirely remaining
+		if existingTask.RemainingMinutes == nil && task.RemainingMinutes == nil {
+			remaining := *task.EstimateMinutes
+			existingTask.RemainingMinutes = &remaining
+		}
+	}
+	if task.RemainingMinutes != nil {
+		existingTask.RemainingMinutes = task.RemainingMinutes
+	}
+	if task.ParentID != nil {
+		if *task.ParentID == 0 {
+			existingTask.ParentID = nil
+		} else if existingTask.ParentID == nil || *existingTask.ParentID != *task.ParentID {
+			if err := u.validateParent(ctx, existingTask, *task.ParentID); err != nil {
+				return err
+			}
+			existingTask.ParentID = task.ParentID
+		}
+	}
+	if err := validateSchedule(existingTask); err != nil {
+		return err
+	}
+	var project *domain.Project
+	if existingTask.Status != previousStatus {
+		project, err = u.projectRepo.GetByID(ctx, existingTask.ProjectID)
+		if err != nil {
+			return err
+		}
+		if err := u.checkTransition(ctx, project, previousStatus, existingTask.Status); err != nil {
+			return err
+		}
+		if existingTask.Status == domain.TaskStatusCompleted {
+			if err := u.checkCompletion(ctx, project, existingTask); err != nil {
+				return err
+			}
+		}
 	}
 	// Update version for optimistic locking
 	existingTask.Version = task.Version
 
-	err = u.taskRepo.Update(ctx, existingTask)
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if project != nil {
+			// Status changes hold the rank lock, which workflow changes
+			// take too, and recheck the status against the workflow then
+			if err := u.taskRepo.LockRanks(ctx, project.ID); err != nil {
+				return err
+			}
+			project, err := u.projectRepo.GetByID(ctx, project.ID)
+			if err != nil {
+				return err
+			}
+			if err := project.EffectiveWorkflow().CheckStatus(existingTask.Status); err != nil {
+				return err
+			}
+			if err := u.checkWIPLimit(ctx, project, existingTask.Status); err != nil {
+				return err
+			}
+		}
+		if err := u.taskRepo.Update(ctx, existingTask); err != nil {
+			return err
+		}
+		if assigneesChanged {
+			if err := u.taskRepo.SetAssignees(ctx, existingTask.ID, existingTask.AssigneeIDs); err != nil {
+				return err
+			}
+		}
+		if labelsChanged {
+			if err := u.taskRepo.SetLabels(ctx, existingTask.ID, existingTask.LabelIDs); err != nil {
+				return err
+			}
+		}
+		if err := u.activityRepo.CreateBatch(ctx, diffTask(ctx, &before, existingTask)); err != nil {
+			return err
+		}
+		// Completing an occurrence brings on the next one
+		if existingTask.RecurrenceID != nil && existingTask.Status == domain.TaskStatusCompleted && previousStatus != domain.TaskStatusCompleted {
+			_, err := u.recurrences.advance(ctx, *existingTask.RecurrenceID, existingTask.ID, time.Time{})
+			return err
+		}
+		return nil
+	})
 	if err == nil {
-		u.redisClient.Del(ctx, fmt.Sprintf("tasks:project:%d", existingTask.ProjectID))
+		invalidateTaskCaches(ctx, u.redisClient, existingTask.ProjectID)
+		invalidateTaskCaches(ctx, u.redisClient, u.relatedProjectIDs(ctx, existingTask.ID)...)
 		// If the project ID changed (unlikely in this app flow but possible), invalidate old one too
 		if existingTask.ProjectID != task.ProjectID && task.ProjectID != 0 {
-			u.redisClient.Del(ctx, fmt.Sprintf("tasks:project:%d", task.ProjectID))
+			invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+		}
+		if assigneesChanged || labelsChanged {
+			if updated, err := u.taskRepo.GetByID(ctx, existingTask.ID); err == nil {
+				existingTask.Assignee, existingTask.Assignees = updated.Assignee, updated.Assignees
+				existingTask.Labels = updated.Labels
+			}
 		}
 		// Update the pointer so the handler gets the updated data back
 		*task = *existingTask
@@ -119,55 +389,1093 @@ func (u *taskUsecase) Delete(c context.Context, id uint) error {
 		return err
 	}
 
-	err = u.taskRepo.Delete(ctx, id)
+	relatedProjectIDs := u.relatedProjectIDs(ctx, id)
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.deleteTask(ctx, existingTask)
+	})
 	if err == nil {
-		u.redisClient.Del(ctx, fmt.Sprintf("tasks:project:%d", existingTask.ProjectID))
+		invalidateTaskCaches(ctx, u.redisClient, existingTask.ProjectID)
+		invalidateTaskCaches(ctx, u.redisClient, relatedProjectIDs...)
 	}
 	return err
 }
 
-func (u *taskUsecase) MarkOverdueTasks(c context.Context) error {
+// deleteTask deletes a task along with its dependencies. Subtasks move up to
+// the deleted task's parent rather than vanish. Callers run it in a
+// transaction.
+func (u *taskUsecase) deleteTask(ctx context.Context, task *domain.Task) error {
+	if err := u.taskRepo.ReparentChildren(ctx, task.ID, task.ParentID); err != nil {
+		return err
+	}
+	if err := u.dependencyRepo.DeleteByTask(ctx, task.ID); err != nil {
+		return err
+	}
+	if task.RecurrenceID != nil {
+		if err := u.recurrences.stop(ctx, *task.RecurrenceID, task.ID); err != nil {
+			return err
+		}
+	}
+	if err := u.taskRepo.Delete(ctx, task.ID); err != nil {
+		return err
+	}
+	activity := newTaskActivity(ctx, task.ID, task.ProjectID, domain.TaskActivityDeleted)
+	return u.activityRepo.CreateBatch(ctx, []domain.TaskActivity{activity})
+}
+
+func (u *taskUsecase) Move(c context.Context, id uint, move domain.TaskMove) (*domain.Task, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if move.BeforeID == nil && move.AfterID == nil && move.Status == "" {
+		return nil, fmt.Errorf("%w: before_id, after_id or status is required", domain.ErrInvalidInput)
+	}
+	if (move.BeforeID != nil && *move.BeforeID == id) || (move.AfterID != nil && *move.AfterID == id) {
+		return nil, fmt.Errorf("%w: a task cannot be moved next to itself", domain.ErrInvalidInput)
+	}
+	if move.Status != "" && move.Version == nil {
+		return nil, fmt.Errorf("%w: version is required to change a task's status", domain.ErrInvalidInput)
+	}
+	task, err := u.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	project, err := u.projectRepo.GetByID(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.taskRepo.LockRanks(ctx, task.ProjectID); err != nil {
+			return err
+		}
+		// Reloaded under the lock so the version check sees earlier moves
+		// and the status check the current workflow
+		task, err = u.taskRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		project, err = u.projectRepo.GetByID(ctx, task.ProjectID)
+		if err != nil {
+			return err
+		}
+		if move.Version != nil && *move.Version != task.Version {
+			return errTaskModified
+		}
+		if move.Status != "" && move.Status != task.Status {
+			if err := u.moveToStatus(ctx, project, task, move.Status); err != nil {
+				return err
+			}
+		}
+		if move.BeforeID == nil && move.AfterID == nil {
+			return nil
+		}
+
+		// At most one rebalance: afterwards every gap has room
+		for attempt := 0; ; attempt++ {
+			rank, ok, err := u.rankForMove(ctx, task, move)
+			if err != nil {
+				return err
+			}
+			if ok {
+				if err := u.taskRepo.SetRank(ctx, task.ID, rank); err != nil {
+					return err
+				}
+				activity := newTaskActivity(ctx, task.ID, task.ProjectID, domain.TaskActivityUpdated)
+				activity.Field = "rank"
+				activity.OldValue = activityString(task.Rank)
+				activity.NewValue = activityString(rank)
+				return u.activityRepo.CreateBatch(ctx, []domain.TaskActivity{activity})
+			}
+			if attempt > 0 {
+				return fmt.Errorf("no rank available for task %d", task.ID)
+			}
+			if err := rebalanceRanks(ctx, u.taskRepo, task.ProjectID); err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+	if move.Status != "" {
+		invalidateTaskCaches(ctx, u.redisClient, u.relatedProjectIDs(ctx, id)...)
+	}
+	return u.taskRepo.GetByID(ctx, id)
+}
+
+// errBulkFailed rolls back a bulk operation once any of its tasks failed.
+var errBulkFailed = errors.New("bulk operation failed")
+
+func (u *taskUsecase) Bulk(c context.Context, op domain.TaskBulkOperation) (*domain.TaskBulkReport, error) {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	// 1. Get potentially affecting projects for cache invalidation
-	// This is a "best effort" invalidation.
-	tasks, err := u.taskRepo.GetOverdueTasks(ctx)
+	projects := make(map[uint]*domain.Project)
+	if err := u.validateBulk(ctx, &op, projects); err != nil {
+		return nil, err
+	}
+	batch := make(map[uint]bool, len(op.Tasks))
+	for _, item := range op.Tasks {
+		batch[item.ID] = true
+	}
+
+	// Status changes and moves take the rank lock of every project whose
+	// columns they change, in ID order so concurrent operations can't
+	// deadlock
+	var lockIDs []uint
+	switch op.Action {
+	case domain.TaskBulkStatus:
+		for _, item := range op.Tasks {
+			if task, err := u.taskRepo.GetByID(ctx, item.ID); err == nil && !containsID(lockIDs, task.ProjectID) {
+				lockIDs = append(lockIDs, task.ProjectID)
+			}
+		}
+		sort.Slice(lockIDs, func(i, j int) bool { return lockIDs[i] < lockIDs[j] })
+	case domain.TaskBulkMove:
+		lockIDs = []uint{op.ProjectID}
+	}
+
+	report := &domain.TaskBulkReport{Action: op.Action, Results: make([]domain.TaskBulkResult, len(op.Tasks))}
+	var affected []uint
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		affected = affected[:0]
+		for _, projectID := range lockIDs {
+			if err := u.taskRepo.LockRanks(ctx, projectID); err != nil {
+				return err
+			}
+		}
+		// Projects read before the locks may have had their workflow
+		// changed since
+		for id := range projects {
+			project, err := u.projectRepo.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			projects[id] = project
+		}
+
+		failed := false
+		for i, item := range op.Tasks {
+			result := domain.TaskBulkResult{ID: item.ID}
+			// Reloaded in the transaction so the version check sees earlier
+			// writes
+			task, err := u.taskRepo.GetByID(ctx, item.ID)
+			if err == nil && task.Version != item.Version {
+				err = errTaskModified
+			}
+			if err == nil {
+				affected = append(affected, task.ProjectID)
+				affected = append(affected, u.relatedProjectIDs(ctx, task.ID)...)
+				result.Version, err = u.applyBulk(ctx, op, task, batch, projects)
+			}
+			if err != nil {
+				outcome, message, ok := bulkOutcome(err)
+				if !ok {
+					return err
+				}
+				result.Outcome, result.Error = outcome, message
+				failed = true
+			} else {
+				result.Outcome = domain.TaskBulkApplied
+			}
+			report.Results[i] = result
+		}
+		if failed {
+			return errBulkFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkFailed) {
+		for i := range report.Results {
+			if report.Results[i].Outcome == domain.TaskBulkApplied {
+				report.Results[i].Outcome = domain.TaskBulkRolledBack
+				report.Results[i].Version = 0
+			}
+		}
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Applied = true
+	if op.Action == domain.TaskBulkMove {
+		affected = append(affected, op.ProjectID)
+	}
+	var projectIDs []uint
+	for _, projectID := range affected {
+		if !containsID(projectIDs, projectID) {
+			projectIDs = append(projectIDs, projectID)
+		}
+	}
+	invalidateTaskCaches(ctx, u.redisClient, projectIDs...)
+	return report, nil
+}
+
+// validateBulk checks a bulk operation before any task is touched, filling
+// projects with the target of a move.
+func (u *taskUsecase) validateBulk(ctx context.Context, op *domain.TaskBulkOperation, projects map[uint]*domain.Project) error {
+	if !op.Action.Valid() {
+		return fmt.Errorf("%w: unknown bulk action %q", domain.ErrInvalidInput, op.Action)
+	}
+	if len(op.Tasks) == 0 {
+		return fmt.Errorf("%w: tasks is required", domain.ErrInvalidInput)
+	}
+	if len(op.Tasks) > domain.MaxBulkTasks {
+		return fmt.Errorf("%w: at most %d tasks can be changed at once", domain.ErrInvalidInput, domain.MaxBulkTasks)
+	}
+	seen := make(map[uint]bool, len(op.Tasks))
+	for _, item := range op.Tasks {
+		if seen[item.ID] {
+			return fmt.Errorf("%w: task %d is listed twice", domain.ErrInvalidInput, item.ID)
+		}
+		seen[item.ID] = true
+	}
+
+	switch op.Action {
+	case domain.TaskBulkStatus:
+		if op.Status == "" {
+			return fmt.Errorf("%w: status is required", domain.ErrInvalidInput)
+		}
+	case domain.TaskBulkReassign:
+		if op.AssigneeIDs == nil {
+			return fmt.Errorf("%w: assignee_ids is required, empty to unassign", domain.ErrInvalidInput)
+		}
+		op.AssigneeIDs = (&domain.Task{AssigneeIDs: op.AssigneeIDs}).AssigneeSet()
+		return u.validateAssignees(ctx, op.AssigneeIDs)
+	case domain.TaskBulkDueDate:
+		if op.DueDate == nil || op.DueDate.IsZero() {
+			return fmt.Errorf("%w: due_date is required", domain.ErrInvalidInput)
+		}
+	case domain.TaskBulkMove, domain.TaskBulkDelete:
+		// The same roles that may delete a single task
+		actor, ok := domain.ActorFromContext(ctx)
+		if !ok || (actor.Role != domain.RoleAdmin && actor.Role != domain.RoleManager) {
+			return fmt.Errorf("%w: only admins and managers can move or delete tasks in bulk", domain.ErrForbidden)
+		}
+		if op.Action == domain.TaskBulkDelete {
+			return nil
+		}
+		if op.ProjectID == 0 {
+			return fmt.Errorf("%w: project_id is required", domain.ErrInvalidInput)
+		}
+		target, err := u.projectRepo.GetByID(ctx, op.ProjectID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: project %d not found", domain.ErrInvalidInput, op.ProjectID)
+		}
+		if err != nil {
+			return err
+		}
+		projects[target.ID] = target
+	}
+	return nil
+}
+
+// applyBulk applies a bulk operation to one task, reloaded under the
+// operation's locks, and returns its new version. Tasks already in the
+// requested state are left alone.
+func (u *taskUsecase) applyBulk(ctx context.Context, op domain.TaskBulkOperation, task *domain.Task, batch map[uint]bool, projects map[uint]*domain.Project) (int, error) {
+	before := *task
+	switch op.Action {
+	case domain.TaskBulkStatus:
+		if task.Status == op.Status {
+			return task.Version, nil
+		}
+		project, ok := projects[task.ProjectID]
+		if !ok {
+			var err error
+			if project, err = u.projectRepo.GetByID(ctx, task.ProjectID); err != nil {
+				return 0, err
+			}
+			projects[project.ID] = project
+		}
+		if err := u.moveToStatus(ctx, project, task, op.Status); err != nil {
+			return 0, err
+		}
+		return task.Version + 1, nil
+	case domain.TaskBulkReassign:
+		// The primary assignee stays while still assigned
+		task.AssigneeIDs = op.AssigneeIDs
+		if task.AssigneeID == nil || !containsID(op.AssigneeIDs, *task.AssigneeID) {
+			task.AssigneeID = nil
+			if len(op.AssigneeIDs) > 0 {
+				task.AssigneeID = &op.AssigneeIDs[0]
+			}
+		}
+		if err := u.saveBulkUpdate(ctx, &before, task); err != nil {
+			return 0, err
+		}
+		if err := u.taskRepo.SetAssignees(ctx, task.ID, op.AssigneeIDs); err != nil {
+			return 0, err
+		}
+		return task.Version + 1, nil
+	case domain.TaskBulkDueDate:
+		task.DueDate = *op.DueDate
+		if err := validateSchedule(task); err != nil {
+			return 0, err
+		}
+		if err := u.saveBulkUpdate(ctx, &before, task); err != nil {
+			return 0, err
+		}
+		return task.Version + 1, nil
+	case domain.TaskBulkMove:
+		if task.ProjectID == op.ProjectID {
+			return task.Version, nil
+		}
+		if err := u.moveToProject(ctx, task, projects[op.ProjectID], batch); err != nil {
+			return 0, err
+		}
+		return task.Version + 1, nil
+	case domain.TaskBulkDelete:
+		return 0, u.deleteTask(ctx, task)
+	}
+	return 0, fmt.Errorf("%w: unknown bulk action %q", domain.ErrInvalidInput, op.Action)
+}
+
+// saveBulkUpdate writes a task changed by a bulk operation and records the
+// change.
+func (u *taskUsecase) saveBulkUpdate(ctx context.Context, before, task *domain.Task) error {
+	if err := u.taskRepo.Update(ctx, task); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errTaskModified
+		}
+		return err
+	}
+	return u.activityRepo.CreateBatch(ctx, diffTask(ctx, before, task))
+}
+
+// moveToProject moves a task to the target project, at the end of its order
+// and out of any sprint. A status the target's workflow lacks becomes its
+// initial status. The parent and subtasks stay linked only when they are
+// in the batch too, and labels and custom field values, which belong to the
+// old project, are dropped. Callers hold the target's rank lock.
+func (u *taskUsecase) moveToProject(ctx context.Context, task *domain.Task, target *domain.Project, batch map[uint]bool) error {
+	before := *task
+	if workflow := target.EffectiveWorkflow(); !workflow.Has(task.Status) {
+		task.Status = workflow.Initial
+	}
+	if err := u.checkWIPLimit(ctx, target, task.Status); err != nil {
+		return err
+	}
+	rank, err := appendRank(ctx, u.taskRepo, target.ID)
 	if err != nil {
 		return err
 	}
 
-	projectIDs := make(map[uint]bool)
-	for _, task := range tasks {
-		projectIDs[task.ProjectID] = true
+	if task.ParentID != nil && !batch[*task.ParentID] {
+		task.ParentID = nil
+	}
+	subtree, err := u.taskRepo.GetSubtree(ctx, task.ID, 1)
+	if err != nil {
+		return err
+	}
+	for _, node := range subtree {
+		if node.Depth == 1 && !batch[node.ID] {
+			if err := u.taskRepo.SetParent(ctx, node.ID, nil); err != nil {
+				return err
+			}
+		}
+	}
+	if len(task.LabelSet()) > 0 {
+		if err := u.taskRepo.SetLabels(ctx, task.ID, nil); err != nil {
+			return err
+		}
+		task.LabelIDs = []uint{}
+	}
+	fields, err := u.fieldRepo.GetByProjectID(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	fieldIDs := make([]uint, len(fields))
+	for i, field := range fields {
+		fieldIDs[i] = field.ID
+	}
+	if err := u.fieldRepo.DeleteValues(ctx, task.ID, fieldIDs); err != nil {
+		return err
+	}
+
+	task.ProjectID = target.ID
+	task.Rank = rank
+	task.SprintID = nil
+	if err := u.taskRepo.MoveToProject(ctx, task); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errTaskModified
+		}
+		return err
+	}
+	return u.activityRepo.CreateBatch(ctx, diffTask(ctx, &before, task))
+}
+
+// bulkOutcome classifies the failure of one task in a bulk operation.
+// Errors it doesn't recognize abort the whole operation.
+func bulkOutcome(err error) (domain.TaskBulkOutcome, string, bool) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.TaskBulkNotFound, "task not found", true
+	case errors.Is(err, domain.ErrConflict):
+		return domain.TaskBulkConflict, err.Error(), true
+	case errors.Is(err, domain.ErrInvalidInput):
+		return domain.TaskBulkInvalid, err.Error(), true
+	case errors.Is(err, domain.ErrForbidden):
+		return domain.TaskBulkForbidden, err.Error(), true
+	}
+	return "", "", false
+}
+
+// errTaskModified reports a stale version on a task write.
+var errTaskModified = fmt.Errorf("%w: task was modified by another user, refresh and try again", domain.ErrConflict)
+
+// moveToStatus applies a board move's status change with the same checks as
+// Update. Callers hold the project's rank lock.
+func (u *taskUsecase) moveToStatus(ctx context.Context, project *domain.Project, task *domain.Task, status domain.TaskStatus) error {
+	previousStatus := task.Status
+	if err := u.checkTransition(ctx, project, previousStatus, status); err != nil {
+		return err
+	}
+	if status == domain.TaskStatusCompleted {
+		if err := u.checkCompletion(ctx, project, task); err != nil {
+			return err
+		}
+	}
+	if err := u.checkWIPLimit(ctx, project, status); err != nil {
+		return err
+	}
+
+	before := *task
+	task.Status = status
+	if err := u.taskRepo.Update(ctx, task); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errTaskModified
+		}
+		return err
+	}
+	if err := u.activityRepo.CreateBatch(ctx, diffTask(ctx, &before, task)); err != nil {
+		return err
+	}
+	if task.RecurrenceID != nil && status == domain.TaskStatusCompleted && previousStatus != domain.TaskStatusCompleted {
+		_, err := u.recurrences.advance(ctx, *task.RecurrenceID, task.ID, time.Time{})
+		return err
+	}
+	return nil
+}
+
+// checkWIPLimit refuses to add a task to a column already at its WIP limit.
+// Callers hold the project's rank lock, so concurrent moves count each
+// other.
+func (u *taskUsecase) checkWIPLimit(ctx context.Context, project *domain.Project, status domain.TaskStatus) error {
+	limit := project.WIPLimits.Limit(status)
+	if limit == nil {
+		return nil
+	}
+	counts, err := u.taskRepo.GetStatusCounts(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	if counts[status] >= int64(*limit) {
+		return fmt.Errorf("%w: the %q column is at its WIP limit of %d", domain.ErrConflict, status, *limit)
 	}
+	return nil
+}
+
+// rankForMove works out the rank placing task between its new neighbours.
+// ok is false when the project has to be rebalanced to make room.
+func (u *taskUsecase) rankForMove(ctx context.Context, task *domain.Task, move domain.TaskMove) (string, bool, error) {
+	var before, after *domain.Task
+	for _, n := range []struct {
+		id   *uint
+		dest **domain.Task
+	}{{move.BeforeID, &before}, {move.AfterID, &after}} {
+		if n.id == nil {
+			continue
+		}
+		neighbor, err := u.taskRepo.GetByID(ctx, *n.id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", false, fmt.Errorf("%w: task %d not found", domain.ErrInvalidInput, *n.id)
+			}
+			return "", false, err
+		}
+		if neighbor.ProjectID != task.ProjectID {
+			return "", false, fmt.Errorf("%w: task %d is in another project", domain.ErrInvalidInput, *n.id)
+		}
+		if move.Status != "" && neighbor.Status != move.Status {
+			return "", false, fmt.Errorf("%w: task %d is no longer in the %q column", domain.ErrConflict, *n.id, move.Status)
+		}
+		*n.dest = neighbor
+	}
+
+	// With one neighbour given, the other side is whatever task currently
+	// follows or precedes it
+	var lo, hi string
+	var err error
+	switch {
+	case after != nil && before != nil:
+		if after.Rank > before.Rank || (after.Rank == before.Rank && after.ID > before.ID) {
+			return "", false, fmt.Errorf("%w: task %d no longer sorts before task %d", domain.ErrConflict, after.ID, before.ID)
+		}
+		lo, hi = after.Rank, before.Rank
+	case after != nil:
+		lo = after.Rank
+		hi, _, err = u.taskRepo.GetNeighborRank(ctx, task.ProjectID, after.Rank, after.ID, task.ID, true)
+	default:
+		hi = before.Rank
+		var found bool
+		lo, found, err = u.taskRepo.GetNeighborRank(ctx, task.ProjectID, before.Rank, before.ID, task.ID, false)
+		if err == nil && found && lo == "" {
+			return "", false, nil
+		}
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	// Unranked neighbours and equal ranks leave no room
+	if (after != nil && after.Rank == "") || (before != nil && before.Rank == "") || (hi != "" && lo >= hi) {
+		return "", false, nil
+	}
+	rank := rankBetween(lo, hi)
+	if len(rank) > maxRankLength {
+		return "", false, nil
+	}
+	return rank, true, nil
+}
+
+func (u *taskUsecase) RebalanceRanks(c context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	projectIDs, err := u.taskRepo.GetProjectsToRebalance(ctx, rebalanceRankLength)
+	if err != nil {
+		return 0, err
+	}
+	for i, projectID := range projectIDs {
+		err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := u.taskRepo.LockRanks(ctx, projectID); err != nil {
+				return err
+			}
+			return rebalanceRanks(ctx, u.taskRepo, projectID)
+		})
+		if err != nil {
+			return i, err
+		}
+		invalidateTaskCaches(ctx, u.redisClient, projectID)
+	}
+	return len(projectIDs), nil
+}
+
+func (u *taskUsecase) Watch(c context.Context, id, userID uint) ([]domain.User, error) {
+	return u.setWatching(c, id, userID, true)
+}
+
+func (u *taskUsecase) Unwatch(c context.Context, id, userID uint) ([]domain.User, error) {
+	return u.setWatching(c, id, userID, false)
+}
+
+func (u *taskUsecase) setWatching(c context.Context, id, userID uint, watch bool) ([]domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: watching a task requires an authenticated user", domain.ErrForbidden)
+	}
+	if userID == 0 {
+		userID = actor.UserID
+	}
+	if userID != actor.UserID && actor.Role == domain.RoleMember {
+		return nil, fmt.Errorf("%w: members can only add or remove themselves as watchers", domain.ErrForbidden)
+	}
+	if _, err := u.taskRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if watch {
+		if err := u.validateAssignees(ctx, []uint{userID}); err != nil {
+			return nil, err
+		}
+		err = u.taskRepo.AddWatcher(ctx, id, userID)
+	} else {
+		err = u.taskRepo.RemoveWatcher(ctx, id, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := u.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Watchers == nil {
+		return []domain.User{}, nil
+	}
+	return task.Watchers, nil
+}
+
+func (u *taskUsecase) MarkOverdueTasks(c context.Context) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
 
-	// 2. Perform Atomic Update
-	if err := u.taskRepo.MarkAsOverdue(ctx); err != nil {
+	// 1. Perform Atomic Update, recording each change as system activity
+	var changes []domain.TaskStatusChange
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		changes, err = u.taskRepo.MarkAsOverdue(ctx)
+		if err != nil {
+			return err
+		}
+		activities := make([]domain.TaskActivity, len(changes))
+		for i, change := range changes {
+			activities[i] = domain.TaskActivity{
+				TaskID:    change.TaskID,
+				ProjectID: change.ProjectID,
+				Action:    domain.TaskActivityUpdated,
+				Field:     "status",
+				OldValue:  activityString(string(change.From)),
+				NewValue:  activityString(string(change.To)),
+			}
+		}
+		return u.activityRepo.CreateBatch(ctx, activities)
+	})
+	if err != nil {
 		return err
 	}
 
-	// 3. Invalidate Caches
+	// 2. Invalidate Caches
+	projectIDs := make(map[uint]bool)
+	for _, change := range changes {
+		projectIDs[change.ProjectID] = true
+	}
 	for projectID := range projectIDs {
-		u.redisClient.Del(ctx, fmt.Sprintf("tasks:project:%d", projectID))
+		invalidateTaskCaches(ctx, u.redisClient, projectID)
 	}
 
 	return nil
 }
 
-func (u *taskUsecase) GetByAssigneeID(c context.Context, assigneeID uint) ([]domain.Task, error) {
+func (u *taskUsecase) PurgeDeletedTasks(c context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		n, err := u.purgeBatch(c, before)
+		purged += n
+		if err != nil || n < purgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
+// purgeBatch purges one batch of deleted tasks. Blobs are released once the
+// rows referencing them are gone.
+func (u *taskUsecase) purgeBatch(c context.Context, before time.Time) (int, error) {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	return u.taskRepo.GetByAssigneeID(ctx, assigneeID)
+	ids, err := u.taskRepo.GetDeletedIDs(ctx, before, purgeBatchSize)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	var keys []string
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		keys, err = u.attachmentRepo.DeleteByTaskIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		return u.taskRepo.Purge(ctx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	releaseBlobs(ctx, u.attachmentRepo, u.blobStore, u.transactor, keys...)
+	return len(ids), nil
+}
+
+func (u *taskUsecase) GetByAssigneeID(c context.Context, assigneeID uint, query domain.TaskQuery) (*domain.TaskPage, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := validateTaskQuery(&query); err != nil {
+		return nil, err
+	}
+	page, err := u.taskRepo.GetByAssigneeID(ctx, assigneeID, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.attachRelations(ctx, page.Tasks); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-func (u *taskUsecase) GetByProjectIDAndAssigneeID(c context.Context, projectID uint, assigneeID uint) ([]domain.Task, error) {
+func (u *taskUsecase) GetByProjectIDAndAssigneeID(c context.Context, projectID uint, assigneeID uint, query domain.TaskQuery, customFields map[uint]string) (*domain.TaskPage, error) {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
+	if query.Sort.Field == "" {
+		query.Sort.Field = domain.TaskSortRank
+	}
+	if err := validateTaskQuery(&query); err != nil {
+		return nil, err
+	}
+	filters, err := resolveCustomFieldFilters(ctx, u.fieldRepo, projectID, customFields)
+	if err != nil {
+		return nil, err
+	}
+	query.Filter.CustomFields = filters
+
 	// Invalidate Project Cache? No, this is a read.
 	// Cache can be tricky here. For now, bypass cache or use specific key.
 	// Given the specific requirement, fetching from DB is safer to ensure privacy.
-	return u.taskRepo.GetByProjectIDAndAssigneeID(ctx, projectID, assigneeID)
+	page, err := u.taskRepo.GetByProjectIDAndAssigneeID(ctx, projectID, assigneeID, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.attachRelations(ctx, page.Tasks); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (u *taskUsecase) GetSubtree(c context.Context, id uint) (*domain.TaskNode, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	nodes, err := u.taskRepo.GetSubtree(ctx, id, domain.MaxTaskDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]domain.Task, len(nodes))
+	for i := range nodes {
+		tasks[i] = nodes[i].Task
+	}
+	if err := u.attachRelations(ctx, tasks); err != nil {
+		return nil, err
+	}
+
+	// Nodes come ordered by depth, so every parent is indexed before its
+	// children
+	byID := make(map[uint]*domain.TaskNode, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		node.Task = tasks[i]
+		node.Children = []*domain.TaskNode{}
+		byID[node.ID] = node
+		if node.Depth > 0 && node.ParentID != nil {
+			if parent, ok := byID[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+			}
+		}
+	}
+	return &nodes[0], nil
+}
+
+// attachRelations fills in the subtask roll-up and the blocking tasks on
+// either side of each task's dependencies.
+func (u *taskUsecase) attachRelations(ctx context.Context, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(tasks))
+	byID := make(map[uint]*domain.Task, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	progress, err := u.taskRepo.GetSubtaskProgress(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		if p, ok := progress[tasks[i].ID]; ok {
+			p.CompletionPercent = percent(p.Completed, p.Total)
+			tasks[i].Subtasks = &p
+		}
+	}
+
+	blockers, err := u.dependencyRepo.GetBlockers(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, edge := range blockers {
+		task := byID[edge.BlockedID]
+		task.BlockedBy = append(task.BlockedBy, edge.Task)
+	}
+	blocked, err := u.dependencyRepo.GetBlocked(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, edge := range blocked {
+		task := byID[edge.BlockerID]
+		task.Blocks = append(task.Blocks, edge.Task)
+	}
+	return nil
+}
+
+// relatedProjectIDs returns the projects of tasks linked to taskID by a
+// dependency, whose cached listings embed its title and status.
+func (u *taskUsecase) relatedProjectIDs(ctx context.Context, taskID uint) []uint {
+	var projectIDs []uint
+	blockers, _ := u.dependencyRepo.GetBlockers(ctx, []uint{taskID})
+	blocked, _ := u.dependencyRepo.GetBlocked(ctx, []uint{taskID})
+	for _, edge := range append(blockers, blocked...) {
+		projectIDs = append(projectIDs, edge.Task.ProjectID)
+	}
+	return projectIDs
+}
+
+// validateParent checks that task can become a subtask of parentID: the
+// parent is in the same project, the move creates no cycle, and the task's
+// own subtree still fits within MaxTaskDepth.
+func (u *taskUsecase) validateParent(ctx context.Context, task *domain.Task, parentID uint) error {
+	if task.ID != 0 && parentID == task.ID {
+		return fmt.Errorf("%w: a task cannot be its own parent", domain.ErrInvalidInput)
+	}
+
+	parent, err := u.taskRepo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: parent task %d not found", domain.ErrInvalidInput, parentID)
+		}
+		return err
+	}
+	if parent.ProjectID != task.ProjectID {
+		return fmt.Errorf("%w: parent task must be in the same project", domain.ErrInvalidInput)
+	}
+
+	ancestors, err := u.taskRepo.GetAncestorIDs(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	height := 0
+	if task.ID != 0 {
+		for _, ancestorID := range ancestors {
+			if ancestorID == task.ID {
+				return fmt.Errorf("%w: task %d is an ancestor of task %d", domain.ErrInvalidInput, task.ID, parentID)
+			}
+		}
+		subtree, err := u.taskRepo.GetSubtree(ctx, task.ID, domain.MaxTaskDepth)
+		if err != nil {
+			return err
+		}
+		for _, node := range subtree {
+			if node.Depth > height {
+				height = node.Depth
+			}
+		}
+	}
+
+	// Depths count from 0 at the top-level task
+	if len(ancestors)+1+height >= domain.MaxTaskDepth {
+		return fmt.Errorf("%w: subtasks can be nested at most %d levels deep", domain.ErrInvalidInput, domain.MaxTaskDepth)
+	}
+	return nil
+}
+
+// checkTransition applies the project's workflow to a status change made by
+// the request's actor.
+func (u *taskUsecase) checkTransition(ctx context.Context, project *domain.Project, from, to domain.TaskStatus) error {
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: status changes require an authenticated user", domain.ErrForbidden)
+	}
+	return project.EffectiveWorkflow().CheckTransition(from, to, actor.Role)
+}
+
+// checkCompletion enforces the project's rules for completing a task: open
+// blockers prevent it unless AllowOpenBlockers is set, and open subtasks
+// prevent it when BlockParentCompletion is set.
+func (u *taskUsecase) checkCompletion(ctx context.Context, project *domain.Project, task *domain.Task) error {
+	if !project.Settings.AllowOpenBlockers {
+		blockers, err := u.dependencyRepo.GetBlockers(ctx, []uint{task.ID})
+		if err != nil {
+			return err
+		}
+		var open []string
+		for _, edge := range blockers {
+			if edge.Task.Status != domain.TaskStatusCompleted {
+				open = append(open, fmt.Sprintf("#%d %q", edge.Task.ID, edge.Task.Title))
+			}
+		}
+		if len(open) > 0 {
+			return fmt.Errorf("%w: task is blocked by open tasks %s", domain.ErrConflict, strings.Join(open, ", "))
+		}
+	}
+
+	if project.Settings.BlockParentCompletion {
+		progress, err := u.taskRepo.GetSubtaskProgress(ctx, []uint{task.ID})
+		if err != nil {
+			return err
+		}
+		if p := progress[task.ID]; p.Completed < p.Total {
+			return fmt.Errorf("%w: %d of %d subtasks are still open", domain.ErrConflict, p.Total-p.Completed, p.Total)
+		}
+	}
+	return nil
+}
+
+// appendRank returns a rank for a task joining the end of the project's
+// order. Callers hold the project's rank lock.
+func appendRank(ctx context.Context, taskRepo domain.TaskRepository, projectID uint) (string, error) {
+	last, err := taskRepo.GetLastRank(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	if rank := rankAfter(last); len(rank) <= maxRankLength {
+		return rank, nil
+	}
+	if err := rebalanceRanks(ctx, taskRepo, projectID); err != nil {
+		return "", err
+	}
+	last, err = taskRepo.GetLastRank(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	return rankAfter(last), nil
+}
+
+// rebalanceRanks respaces the project's ranks evenly, keeping their order.
+// Callers hold the project's rank lock.
+func rebalanceRanks(ctx context.Context, taskRepo domain.TaskRepository, projectID uint) error {
+	ids, err := taskRepo.GetRankOrder(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return taskRepo.SetRanks(ctx, ids, spreadRanks(len(ids)))
+}
+
+// mergeAssignees applies an update's assignees to the existing task, leaving
+// the full set in AssigneeIDs, and reports whether the update named any.
+func mergeAssignees(existing, changes *domain.Task) bool {
+	if changes.AssigneeIDs != nil {
+		// The primary assignee stays while still assigned, unless a new one
+		// is named alongside
+		existing.AssigneeIDs = changes.AssigneeIDs
+		if changes.AssigneeID != nil {
+			existing.AssigneeID = changes.AssigneeID
+		} else if existing.AssigneeID == nil || !containsID(changes.AssigneeIDs, *existing.AssigneeID) {
+			existing.AssigneeID = nil
+			if len(changes.AssigneeIDs) > 0 {
+				existing.AssigneeID = &changes.AssigneeIDs[0]
+			}
+		}
+	} else if changes.AssigneeID != nil {
+		// Clients that only know assignee_id replace the primary assignee.
+		// Non-nil, so AssigneeSet doesn't fall back to the loaded assignees
+		others := []uint{}
+		for _, user := range existing.Assignees {
+			if existing.AssigneeID == nil || user.ID != *existing.AssigneeID {
+				others = append(others, user.ID)
+			}
+		}
+		existing.AssigneeIDs = others
+		existing.AssigneeID = changes.AssigneeID
+	} else {
+		return false
+	}
+	existing.AssigneeIDs = existing.AssigneeSet()
+	return true
+}
+
+// mergeSchedule applies an update's start date and duration to the existing
+// task, including the clear flags.
+func mergeSchedule(existing, changes *domain.Task) error {
+	if changes.ClearStartDate && changes.StartDate != nil {
+		return fmt.Errorf("%w: start_date and clear_start_date are mutually exclusive", domain.ErrInvalidInput)
+	}
+	if changes.ClearDurationDays && changes.DurationDays != nil {
+		return fmt.Errorf("%w: duration_days and clear_duration_days are mutually exclusive", domain.ErrInvalidInput)
+	}
+	if changes.StartDate != nil || changes.ClearStartDate {
+		existing.StartDate = changes.StartDate
+	}
+	if changes.DurationDays != nil || changes.ClearDurationDays {
+		existing.DurationDays = changes.DurationDays
+	}
+	return nil
+}
+
+// validateAssignees checks that every user exists and is active.
+func (u *taskUsecase) validateAssignees(ctx context.Context, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if len(userIDs) > maxTaskAssignees {
+		return fmt.Errorf("%w: a task can have at most %d assignees", domain.ErrInvalidInput, maxTaskAssignees)
+	}
+	users, err := u.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	active := make(map[uint]bool, len(users))
+	for _, user := range users {
+		active[user.ID] = user.Active
+	}
+	for _, id := range userIDs {
+		if !active[id] {
+			return fmt.Errorf("%w: user %d not found or deactivated", domain.ErrInvalidInput, id)
+		}
+	}
+	return nil
+}
+
+// validateLabels checks that every label belongs to the project and returns
+// the IDs without duplicates.
+func (u *taskUsecase) validateLabels(ctx context.Context, projectID uint, labelIDs []uint) ([]uint, error) {
+	if len(labelIDs) == 0 {
+		return labelIDs, nil
+	}
+	unique := make([]uint, 0, len(labelIDs))
+	for _, id := range labelIDs {
+		if !containsID(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > maxTaskLabels {
+		return nil, fmt.Errorf("%w: a task can have at most %d labels", domain.ErrInvalidInput, maxTaskLabels)
+	}
+	labels, err := u.labelRepo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	inProject := make(map[uint]bool, len(labels))
+	for _, label := range labels {
+		inProject[label.ID] = label.ProjectID == projectID
+	}
+	for _, id := range unique {
+		if !inProject[id] {
+			return nil, fmt.Errorf("%w: label %d does not belong to the project", domain.ErrInvalidInput, id)
+		}
+	}
+	return unique, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSchedule(task *domain.Task) error {
+	if task.DurationDays != nil && *task.DurationDays < 0 {
+		return fmt.Errorf("%w: duration_days cannot be negative", domain.ErrInvalidInput)
+	}
+	if task.EstimateMinutes != nil && *task.EstimateMinutes < 0 {
+		return fmt.Errorf("%w: estimate_minutes cannot be negative", domain.ErrInvalidInput)
+	}
+	if task.RemainingMinutes != nil && *task.RemainingMinutes < 0 {
+		return fmt.Errorf("%w: remaining_minutes cannot be negative", domain.ErrInvalidInput)
+	}
+	if task.StartDate != nil && !task.DueDate.IsZero() && task.DueDate.Before(*task.StartDate) {
+		return fmt.Errorf("%w: due_date cannot be before start_date", domain.ErrInvalidInput)
+	}
+	return nil
 }
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestMergeAssignees(t *testing.T) {
+	users := func(ids ...uint) []domain.User {
+		list := make([]domain.User, len(ids))
+		for i, id := range ids {
+			list[i].ID = id
+		}
+		return list
+	}
+
+	tests := []struct {
+		name        string
+		existing    domain.Task
+		changes     domain.Task
+		wantChanged bool
+		wantPrimary *uint
+		wantIDs     []uint
+	}{
+		{
+			name:        "no assignee fields",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1, 2)},
+			wantChanged: false,
+			wantPrimary: uintPtr(1),
+		},
+		{
+			name:        "assignee_id replaces the only assignee",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1)},
+			changes:     domain.Task{AssigneeID: uintPtr(2)},
+			wantChanged: true,
+			wantPrimary: uintPtr(2),
+			wantIDs:     []uint{2},
+		},
+		{
+			name:        "assignee_id replaces the primary and keeps the others",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1, 3)},
+			changes:     domain.Task{AssigneeID: uintPtr(2)},
+			wantChanged: true,
+			wantPrimary: uintPtr(2),
+			wantIDs:     []uint{2, 3},
+		},
+		{
+			name:        "assignee_id on an unassigned task",
+			existing:    domain.Task{},
+			changes:     domain.Task{AssigneeID: uintPtr(4)},
+			wantChanged: true,
+			wantPrimary: uintPtr(4),
+			wantIDs:     []uint{4},
+		},
+		{
+			name:        "assignee_ids keeps a primary still assigned",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1, 2)},
+			changes:     domain.Task{AssigneeIDs: []uint{2, 1}},
+			wantChanged: true,
+			wantPrimary: uintPtr(1),
+			wantIDs:     []uint{1, 2},
+		},
+		{
+			name:        "assignee_ids promotes the first when the primary is dropped",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1)},
+			changes:     domain.Task{AssigneeIDs: []uint{3, 2}},
+			wantChanged: true,
+			wantPrimary: uintPtr(3),
+			wantIDs:     []uint{3, 2},
+		},
+		{
+			name:        "empty assignee_ids unassigns",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1, 2)},
+			changes:     domain.Task{AssigneeIDs: []uint{}},
+			wantChanged: true,
+			wantIDs:     []uint{},
+		},
+		{
+			name:        "assignee_ids with a new primary",
+			existing:    domain.Task{AssigneeID: uintPtr(1), Assignees: users(1)},
+			changes:     domain.Task{AssigneeIDs: []uint{1, 2}, AssigneeID: uintPtr(2)},
+			wantChanged: true,
+			wantPrimary: uintPtr(2),
+			wantIDs:     []uint{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := tt.existing
+			changed := mergeAssignees(&existing, &tt.changes)
+			if changed != tt.wantChanged {
+				t.Fatalf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(existing.AssigneeID, tt.wantPrimary) {
+				t.Errorf("primary = %v, want %v", existing.AssigneeID, tt.wantPrimary)
+			}
+			if changed && !reflect.DeepEqual(existing.AssigneeIDs, tt.wantIDs) {
+				t.Errorf("assignees = %v, want %v", existing.AssigneeIDs, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestMergeSchedule(t *testing.T) {
+	start := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	later := start.AddDate(0, 0, 7)
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name         string
+		changes      domain.Task
+		wantErr      bool
+		wantStart    *time.Time
+		wantDuration *int
+	}{
+		{name: "nothing named", wantStart: &start, wantDuration: intPtr(3)},
+		{
+			name:         "new values",
+			changes:      domain.Task{StartDate: &later, DurationDays: intPtr(5)},
+			wantStart:    &later,
+			wantDuration: intPtr(5),
+		},
+		{
+			name:         "clear start date",
+			changes:      domain.Task{ClearStartDate: true},
+			wantDuration: intPtr(3),
+		},
+		{
+			name:      "clear duration",
+			changes:   domain.Task{ClearDurationDays: true},
+			wantStart: &start,
+		},
+		{
+			name:    "clear both",
+			changes: domain.Task{ClearStartDate: true, ClearDurationDays: true},
+		},
+		{
+			name:    "start date with its clear flag",
+			changes: domain.Task{StartDate: &later, ClearStartDate: true},
+			wantErr: true,
+		},
+		{
+			name:    "duration with its clear flag",
+			changes: domain.Task{DurationDays: intPtr(5), ClearDurationDays: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := domain.Task{StartDate: &start, DurationDays: intPtr(3)}
+			err := mergeSchedule(&existing, &tt.changes)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Fatalf("error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(existing.StartDate, tt.wantStart) {
+				t.Errorf("start date = %v, want %v", existing.StartDate, tt.wantStart)
+			}
+			if !reflect.DeepEqual(existing.DurationDays, tt.wantDuration) {
+				t.Errorf("duration = %v, want %v", existing.DurationDays, tt.wantDuration)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// runningTimersKey is a hash of user ID to the JSON of their running
+	// timer. The database snapshot taken by PersistTimers backs it up.
+	runningTimersKey = "timers:running"
+	// maxEntryMinutes caps a single entry at one day; timers left running
+	// for longer log a day.
+	maxEntryMinutes     = 24 * 60
+	maxTimeEntryNoteLen = 500
+)
+
+type timeTrackingUsecase struct {
+	entryRepo      domain.TimeEntryRepository
+	taskRepo       domain.TaskRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewTimeTrackingUsecase(entryRepo domain.TimeEntryRepository, taskRepo domain.TaskRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.TimeTrackingUsecase {
+	return &timeTrackingUsecase{
+		entryRepo:      entryRepo,
+		taskRepo:       taskRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func validateTimeEntry(entry *domain.TimeEntry) error {
+	if entry.Minutes < 1 || entry.Minutes > maxEntryMinutes {
+		return fmt.Errorf("%w: minutes must be between 1 and %d", domain.ErrInvalidInput, maxEntryMinutes)
+	}
+	if entry.StartedAt.After(time.Now()) {
+		return fmt.Errorf("%w: started_at cannot be in the future", domain.ErrInvalidInput)
+	}
+	entry.Note = strings.TrimSpace(entry.Note)
+	if utf8.RuneCountInString(entry.Note) > maxTimeEntryNoteLen {
+		return fmt.Errorf("%w: note must be at most %d characters", domain.ErrInvalidInput, maxTimeEntryNoteLen)
+	}
+	return nil
+}
+
+func (u *timeTrackingUsecase) LogTime(c context.Context, taskID uint, entry *domain.TimeEntry) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: logging time requires an authenticated user", domain.ErrForbidden)
+	}
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	// Without a start the time is taken to end now
+	if entry.StartedAt.IsZero() {
+		entry.StartedAt = time.Now().Add(-time.Duration(entry.Minutes) * time.Minute)
+	}
+	if err := validateTimeEntry(entry); err != nil {
+		return err
+	}
+
+	entry.ID = 0
+	entry.TaskID = taskID
+	entry.ProjectID = task.ProjectID
+	entry.UserID = actor.UserID
+	entry.User = nil
+	if err := u.createEntry(ctx, entry); err != nil {
+		return err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, task.ProjectID)
+
+	created, err := u.entryRepo.GetByID(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+	*entry = *created
+	return nil
+}
+
+// createEntry records the entry and counts it towards its task's logged
+// time.
+func (u *timeTrackingUsecase) createEntry(ctx context.Context, entry *domain.TimeEntry) error {
+	return u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.entryRepo.Create(ctx, entry); err != nil {
+			return err
+		}
+		return u.addLoggedMinutes(ctx, entry.TaskID, entry.Minutes)
+	})
+}
+
+// addLoggedMinutes updates the task's logged and remaining time. Entries
+// outlive deleted tasks for reporting, so a missing task is not an error.
+func (u *timeTrackingUsecase) addLoggedMinutes(ctx context.Context, taskID uint, minutes int) error {
+	err := u.taskRepo.AddLoggedMinutes(ctx, taskID, minutes)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (u *timeTrackingUsecase) GetEntries(c context.Context, taskID uint) ([]domain.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.taskRepo.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return u.entryRepo.GetByTaskID(ctx, taskID)
+}
+
+func (u *timeTrackingUsecase) UpdateEntry(c context.Context, taskID, id uint, changes domain.TimeEntryChanges) (*domain.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	entry, err := u.taskEntry(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || actor.UserID != entry.UserID {
+		return nil, fmt.Errorf("%w: only the author can edit a time entry", domain.ErrForbidden)
+	}
+
+	previousMinutes := entry.Minutes
+	if changes.Minutes != 0 {
+		entry.Minutes = changes.Minutes
+	}
+	if !changes.StartedAt.IsZero() {
+		entry.StartedAt = changes.StartedAt
+	}
+	if changes.Note != nil {
+		entry.Note = *changes.Note
+	}
+	if err := validateTimeEntry(entry); err != nil {
+		return nil, err
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.entryRepo.Update(ctx, entry); err != nil {
+			return err
+		}
+		if entry.Minutes == previousMinutes {
+			return nil
+		}
+		return u.addLoggedMinutes(ctx, taskID, entry.Minutes-previousMinutes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, entry.ProjectID)
+
+	return u.entryRepo.GetByID(ctx, id)
+}
+
+func (u *timeTrackingUsecase) DeleteEntry(c context.Context, taskID, id uint) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	entry, err := u.taskEntry(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || (actor.UserID != entry.UserID && actor.Role == domain.RoleMember) {
+		return fmt.Errorf("%w: only the author, an admin or a manager can delete a time entry", domain.ErrForbidden)
+	}
+
+	// The time goes back onto the remaining estimate
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.entryRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return u.addLoggedMinutes(ctx, taskID, -entry.Minutes)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, entry.ProjectID)
+	return nil
+}
+
+// taskEntry loads a time entry, treating one on another task as missing.
+func (u *timeTrackingUsecase) taskEntry(ctx context.Context, taskID, id uint) (*domain.TimeEntry, error) {
+	entry, err := u.entryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.TaskID != taskID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return entry, nil
+}
+
+func (u *timeTrackingUsecase) StartTimer(c context.Context, taskID uint, note string) (*domain.RunningTimer, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: timers require an authenticated user", domain.ErrForbidden)
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxTimeEntryNoteLen {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", domain.ErrInvalidInput, maxTimeEntryNoteLen)
+	}
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	// Restores a timer Redis lost, so it is seen as running below
+	if _, err := u.loadTimer(ctx, actor.UserID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	timer := &domain.RunningTimer{
+		UserID:    actor.UserID,
+		TaskID:    taskID,
+		ProjectID: task.ProjectID,
+		StartedAt: time.Now(),
+		Note:      note,
+	}
+	jsonTimer, _ := json.Marshal(timer)
+	started, err := u.redisClient.HSetNX(ctx, runningTimersKey, timerField(actor.UserID), jsonTimer).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, fmt.Errorf("%w: a timer is already running; stop it first", domain.ErrConflict)
+	}
+	return timer, nil
+}
+
+func (u *timeTrackingUsecase) StopTimer(c context.Context) (*domain.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: timers require an authenticated user", domain.ErrForbidden)
+	}
+	timer, err := u.loadTimer(ctx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Only the request that removes the timer logs it
+	removed, err := u.redisClient.HDel(ctx, runningTimersKey, timerField(actor.UserID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// The task may have moved to another project while the timer ran
+	projectID := timer.ProjectID
+	if task, err := u.taskRepo.GetByID(ctx, timer.TaskID); err == nil {
+		projectID = task.ProjectID
+	}
+	minutes := int(math.Round(time.Since(timer.StartedAt).Minutes()))
+	minutes = max(1, min(minutes, maxEntryMinutes))
+	entry := &domain.TimeEntry{
+		TaskID:    timer.TaskID,
+		ProjectID: projectID,
+		UserID:    actor.UserID,
+		StartedAt: timer.StartedAt,
+		Minutes:   minutes,
+		Note:      timer.Note,
+	}
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.entryRepo.StopTimer(ctx, timer); err != nil {
+			return err
+		}
+		return u.createEntry(ctx, entry)
+	})
+	if err != nil {
+		// Keep the timer running so the time isn't lost
+		jsonTimer, _ := json.Marshal(timer)
+		u.redisClient.HSetNX(ctx, runningTimersKey, timerField(actor.UserID), jsonTimer)
+		return nil, err
+	}
+	invalidateTaskCaches(ctx, u.redisClient, entry.ProjectID)
+
+	return u.entryRepo.GetByID(ctx, entry.ID)
+}
+
+func (u *timeTrackingUsecase) GetTimer(c context.Context) (*domain.RunningTimer, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: timers require an authenticated user", domain.ErrForbidden)
+	}
+	return u.loadTimer(ctx, actor.UserID)
+}
+
+// loadTimer returns the user's running timer. A timer only found in the
+// database snapshot, as after Redis lost its data, is put back into Redis.
+func (u *timeTrackingUsecase) loadTimer(ctx context.Context, userID uint) (*domain.RunningTimer, error) {
+	cached, err := u.redisClient.HGet(ctx, runningTimersKey, timerField(userID)).Result()
+	if err == nil {
+		var timer domain.RunningTimer
+		if err := json.Unmarshal([]byte(cached), &timer); err != nil {
+			return nil, err
+		}
+		return &timer, nil
+	}
+	if err != redis.Nil {
+		return nil, err
+	}
+
+	timer, err := u.entryRepo.GetTimer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	jsonTimer, _ := json.Marshal(timer)
+	u.redisClient.HSetNX(ctx, runningTimersKey, timerField(userID), jsonTimer)
+	return timer, nil
+}
+
+func (u *timeTrackingUsecase) PersistTimers(c context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	cached, err := u.redisClient.HGetAll(ctx, runningTimersKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	timers := make([]domain.RunningTimer, 0, len(cached))
+	for _, value := range cached {
+		var timer domain.RunningTimer
+		if err := json.Unmarshal([]byte(value), &timer); err != nil {
+			continue
+		}
+		timer.UpdatedAt = now
+		timers = append(timers, timer)
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.entryRepo.SaveTimers(ctx, timers)
+	})
+	return len(timers), err
+}
+
+func timerField(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
+
+func (u *timeTrackingUsecase) GetReport(c context.Context, query domain.TimeReportQuery) (*domain.TimeReport, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: reports require an authenticated user", domain.ErrForbidden)
+	}
+	if actor.Role == domain.RoleMember {
+		query.UserID = &actor.UserID
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = domain.TimeReportByUser
+	} else if !query.GroupBy.Valid() {
+		return nil, fmt.Errorf("%w: group_by must be user, project, task or day", domain.ErrInvalidInput)
+	}
+	if query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidInput)
+	}
+	if query.To.Sub(query.From) > maxReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: date range cannot exceed %d days", domain.ErrInvalidInput, maxReportDays)
+	}
+
+	rows, err := u.entryRepo.GetReport(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.TimeReport{
+		From:    query.From.Format("2006-01-02"),
+		To:      query.To.Format("2006-01-02"),
+		GroupBy: query.GroupBy,
+		Rows:    rows,
+	}
+	if report.Rows == nil {
+		report.Rows = []domain.TimeReportRow{}
+	}
+	for i := range report.Rows {
+		report.Rows[i].Hours = minutesToHours(report.Rows[i].Minutes)
+		report.TotalMinutes += report.Rows[i].Minutes
+	}
+	report.TotalHours = minutesToHours(report.TotalMinutes)
+	return report, nil
+}
+
+// minutesToHours converts to hours rounded to two decimals.
+func minutesToHours(minutes int64) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
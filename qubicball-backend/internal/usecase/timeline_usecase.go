@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"qubicball-backend/internal/domain"
+)
+
+type timelineUsecase struct {
+	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	sprintRepo     domain.SprintRepository
+	dependencyRepo domain.TaskDependencyRepository
+	contextTimeout time.Duration
+}
+
+func NewTimelineUsecase(projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, sprintRepo domain.SprintRepository, dependencyRepo domain.TaskDependencyRepository, timeout time.Duration) domain.TimelineUsecase {
+	return &timelineUsecase{
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		sprintRepo:     sprintRepo,
+		dependencyRepo: dependencyRepo,
+		contextTimeout: timeout,
+	}
+}
+
+// timelineSpan derives a task's start and end:
+//   - start and duration: start until start + duration
+//   - start only: start until the due date (or start when due is earlier)
+//   - duration only: due date - duration until the due date
+//   - neither: a milestone on the due date
+//
+// ok is false when the task has no date to place it by.
+func timelineSpan(task *domain.Task) (start, end time.Time, ok bool) {
+	due := task.DueDate
+	switch {
+	case task.StartDate != nil && task.DurationDays != nil:
+		start = *task.StartDate
+		end = start.AddDate(0, 0, *task.DurationDays)
+	case task.StartDate != nil:
+		start = *task.StartDate
+		end = start
+		if due.After(start) {
+			end = due
+		}
+	case due.IsZero():
+		return time.Time{}, time.Time{}, false
+	case task.DurationDays != nil:
+		end = due
+		start = due.AddDate(0, 0, -*task.DurationDays)
+	default:
+		start, end = due, due
+	}
+	return start, end, true
+}
+
+func (u *timelineUsecase) GetProjectTimeline(c context.Context, projectID uint, groupBy domain.TimelineGrouping, assigneeID *uint) (*domain.ProjectTimeline, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if groupBy != domain.TimelineByAssignee && groupBy != domain.TimelineBySprint {
+		return nil, fmt.Errorf("%w: group_by must be %q or %q", domain.ErrInvalidInput, domain.TimelineByAssignee, domain.TimelineBySprint)
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	var page *domain.TaskPage
+	var err error
+	if assigneeID != nil {
+		page, err = u.taskRepo.GetByProjectIDAndAssigneeID(ctx, projectID, *assigneeID, domain.TaskQuery{})
+	} else {
+		page, err = u.taskRepo.GetByProjectID(ctx, projectID, domain.TaskQuery{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	tasks := page.Tasks
+
+	labels := make(map[uint]string)
+	if groupBy == domain.TimelineBySprint {
+		sprints, err := u.sprintRepo.GetByProjectID(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sprint := range sprints {
+			labels[sprint.ID] = sprint.Name
+		}
+	}
+
+	timeline := &domain.ProjectTimeline{
+		ProjectID:   projectID,
+		GroupBy:     groupBy,
+		Groups:      []domain.TimelineGroup{},
+		Links:       []domain.TimelineLink{},
+		Unscheduled: []domain.TimelineItem{},
+	}
+	groups := make(map[uint]*domain.TimelineGroup)
+	var ungrouped *domain.TimelineGroup
+
+	for i := range tasks {
+		task := &tasks[i]
+		item := domain.TimelineItem{
+			TaskID:     task.ID,
+			Title:      task.Title,
+			Status:     task.Status,
+			AssigneeID: task.AssigneeID,
+			SprintID:   task.SprintID,
+			DueDate:    task.DueDate,
+		}
+		start, end, ok := timelineSpan(task)
+		if !ok {
+			timeline.Unscheduled = append(timeline.Unscheduled, item)
+			continue
+		}
+		item.Start, item.End = start, end
+		item.Milestone = start.Equal(end)
+
+		if timeline.Start == nil || start.Before(*timeline.Start) {
+			timeline.Start = &start
+		}
+		if timeline.End == nil || end.After(*timeline.End) {
+			timeline.End = &end
+		}
+
+		groupID := task.AssigneeID
+		if groupBy == domain.TimelineBySprint {
+			groupID = task.SprintID
+		}
+		if groupID == nil {
+			if ungrouped == nil {
+				label := "Unassigned"
+				if groupBy == domain.TimelineBySprint {
+					label = "Backlog"
+				}
+				ungrouped = &domain.TimelineGroup{Label: label}
+			}
+			ungrouped.Items = append(ungrouped.Items, item)
+			continue
+		}
+
+		group, ok := groups[*groupID]
+		if !ok {
+			id := *groupID
+			label := labels[id]
+			if groupBy == domain.TimelineByAssignee && task.Assignee != nil {
+				label = task.Assignee.Name
+			}
+			group = &domain.TimelineGroup{ID: &id, Label: label}
+			groups[id] = group
+		}
+		group.Items = append(group.Items, item)
+	}
+
+	for _, group := range groups {
+		timeline.Groups = append(timeline.Groups, *group)
+	}
+	sort.Slice(timeline.Groups, func(i, j int) bool {
+		return timeline.Groups[i].Label < timeline.Groups[j].Label
+	})
+	if ungrouped != nil {
+		timeline.Groups = append(timeline.Groups, *ungrouped)
+	}
+	for i := range timeline.Groups {
+		items := timeline.Groups[i].Items
+		sort.SliceStable(items, func(a, b int) bool { return items[a].Start.Before(items[b].Start) })
+	}
+
+	// Only links between tasks on this timeline can be drawn
+	ids := make([]uint, len(tasks))
+	onTimeline := make(map[uint]bool, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		onTimeline[tasks[i].ID] = true
+	}
+	blocked, err := u.dependencyRepo.GetBlocked(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, edge := range blocked {
+		if onTimeline[edge.BlockedID] {
+			timeline.Links = append(timeline.Links, domain.TimelineLink{
+				FromTaskID: edge.BlockerID,
+				ToTaskID:   edge.BlockedID,
+				Type:       "blocks",
+			})
+		}
+	}
+
+	return timeline, nil
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"qubicball-backend/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type workflowUsecase struct {
+	projectRepo    domain.ProjectRepository
+	taskRepo       domain.TaskRepository
+	transactor     domain.Transactor
+	redisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewWorkflowUsecase(projectRepo domain.ProjectRepository, taskRepo domain.TaskRepository, transactor domain.Transactor, redisClient *redis.Client, timeout time.Duration) domain.WorkflowUsecase {
+	return &workflowUsecase{
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		transactor:     transactor,
+		redisClient:    redisClient,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *workflowUsecase) GetWorkflow(c context.Context, projectID uint) (*domain.Workflow, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return project.EffectiveWorkflow(), nil
+}
+
+func (u *workflowUsecase) UpdateWorkflow(c context.Context, projectID uint, workflow *domain.Workflow) (*domain.Workflow, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok || !actor.CanManage(project) {
+		return nil, fmt.Errorf("%w: only the project owner or an admin can change the workflow", domain.ErrForbidden)
+	}
+
+	effective := workflow
+	if effective == nil {
+		effective = domain.DefaultWorkflow()
+	}
+	if err := effective.Validate(); err != nil {
+		return nil, err
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Holding the lock task moves take keeps tasks from entering a
+		// status between the check below and the write
+		if err := u.taskRepo.LockRanks(ctx, projectID); err != nil {
+			return err
+		}
+		project, err := u.projectRepo.GetByID(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		// Tasks must not be stranded in a status the workflow no longer has
+		counts, err := u.taskRepo.GetStatusCounts(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		var stranded []string
+		for status, count := range counts {
+			if !effective.Has(status) {
+				stranded = append(stranded, fmt.Sprintf("%q (%d tasks)", status, count))
+			}
+		}
+		if len(stranded) > 0 {
+			sort.Strings(stranded)
+			return fmt.Errorf("%w: tasks still use statuses missing from the workflow: %s", domain.ErrConflict, strings.Join(stranded, ", "))
+		}
+
+		if err := u.projectRepo.UpdateWorkflow(ctx, projectID, workflow); err != nil {
+			return err
+		}
+		// Limits on statuses the workflow dropped would come back with them
+		if pruned := project.WIPLimits.Prune(effective); len(pruned) != len(project.WIPLimits) {
+			return u.projectRepo.UpdateWIPLimits(ctx, projectID, pruned)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.redisClient.Del(ctx, fmt.Sprintf("project:%d", projectID))
+	bumpProjectListGeneration(ctx, u.redisClient)
+	// The board's columns follow the workflow
+	invalidateTaskCaches(ctx, u.redisClient, projectID)
+
+	return effective, nil
+}